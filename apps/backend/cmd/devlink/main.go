@@ -3,12 +3,21 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"devlink/internal/config"
 	"devlink/internal/db"
 	"devlink/internal/handlers"
+	"devlink/internal/mailer"
+	"devlink/internal/middleware"
+	"devlink/internal/oidc"
+	"devlink/internal/password"
 	"devlink/internal/repository"
 	"devlink/internal/routes"
+	"devlink/internal/urlnorm"
+	"devlink/internal/utils"
 )
 
 func main() {
@@ -16,14 +25,52 @@ func main() {
 	port := config.GetEnv("PORT", "8080")
 	dbURL := config.GetEnv("DB_URL", "devlink.db")
 
+	keyring, err := utils.LoadKeyringFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	utils.SetKeyring(keyring)
+	go reloadKeysOnSignal(keyring)
+
 	dbConn := db.InitDB(dbURL)
 
-	userRepo := repository.NewUserRepository(dbConn)
-	resourceRepo := repository.NewResourceRepository(dbConn)
+	urls := urlnorm.NewFromEnv()
+	repos := repository.NewRepositories(dbConn, urls)
+
+	// Bootstrap admins from ADMIN_EMAILS; only verified addresses are promoted
+	if emails := config.GetEnvList("ADMIN_EMAILS"); len(emails) > 0 {
+		promoted, err := repos.User.PromoteToAdmin(emails)
+		if err != nil {
+			log.Fatalf("Failed to promote admins: %v", err)
+		}
+		if promoted > 0 {
+			log.Printf("Promoted %d user(s) from ADMIN_EMAILS to admin", promoted)
+		}
+	}
+
+	// Link resources saved before canonical links existed; duplicates within a library stay unlinked
+	report, err := repos.Resource.BackfillCanonicalLinks()
+	if err != nil {
+		log.Fatalf("Failed to link resources to canonical links: %v", err)
+	}
+	if report.Linked > 0 || len(report.Duplicates) > 0 {
+		log.Printf("Linked %d resource(s) to canonical links; %d duplicate(s) left unlinked, run renormalize to list them",
+			report.Linked, len(report.Duplicates))
+	}
+
+	mail := mailer.NewFromEnv()
+
+	providers := oidc.LoadProvidersFromEnv()
+
+	hasher, err := password.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure password hashing: %v", err)
+	}
 
-	handlers := handlers.NewHandlersContainer(userRepo, resourceRepo)
+	handlers := handlers.NewHandlersContainer(repos, mail, providers, hasher, urls)
+	authMiddleware := middleware.NewAuthMiddleware(repos.Token, repos.AccessToken, repos.User)
 
-	r := routes.SetupRouter(handlers)
+	r := routes.SetupRouter(handlers, authMiddleware)
 
 	log.Printf("Server is running on port %s", port)
 	if err := http.ListenAndServe(":"+port, r); err != nil {
@@ -31,3 +78,16 @@ func main() {
 	}
 	log.Println("Server stopped")
 }
+
+// reloadKeysOnSignal picks up rotated signing keys on SIGHUP without dropping connections
+func reloadKeysOnSignal(keyring *utils.Keyring) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := keyring.Reload(); err != nil {
+			log.Printf("Failed to reload JWT keys, keeping the current ones: %v", err)
+			continue
+		}
+		log.Println("JWT keys reloaded")
+	}
+}
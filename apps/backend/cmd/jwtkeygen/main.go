@@ -0,0 +1,57 @@
+// Command jwtkeygen writes a new signing key into JWT_KEYS_DIR for key rotation.
+// The key is named after the current time so it sorts last and becomes the active key
+// on the next start or SIGHUP; older keys stay in the directory to verify existing tokens.
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"devlink/internal/config"
+)
+
+func main() {
+	config.LoadEnv()
+	dir := flag.String("dir", config.GetEnv("JWT_KEYS_DIR", "keys"), "directory holding the signing keys")
+	alg := flag.String("alg", "EdDSA", "signing algorithm: EdDSA or RS256")
+	flag.Parse()
+
+	var key interface{}
+	var err error
+	switch *alg {
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case "RS256":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		log.Fatalf("Unsupported algorithm %q", *alg)
+	}
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		log.Fatalf("Failed to encode key: %v", err)
+	}
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatalf("Failed to create key directory: %v", err)
+	}
+
+	kid := time.Now().UTC().Format("20060102T150405Z")
+	path := filepath.Join(*dir, kid+".pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		log.Fatalf("Failed to write key: %v", err)
+	}
+	fmt.Printf("Wrote %s key %s\n", *alg, path)
+}
@@ -0,0 +1,22 @@
+// Command powsolve solves a registration challenge from GET /users/register/challenge,
+// for scripts and local testing without a browser.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"devlink/internal/utils"
+)
+
+func main() {
+	challenge := flag.String("challenge", "", "challenge returned by the server")
+	difficulty := flag.Int("difficulty", 0, "leading zero bits required")
+	flag.Parse()
+
+	if *challenge == "" || *difficulty <= 0 {
+		log.Fatal("usage: powsolve -challenge <challenge> -difficulty <bits>")
+	}
+	fmt.Println(utils.SolvePoW(*challenge, *difficulty))
+}
@@ -0,0 +1,51 @@
+// Command renormalize runs every stored resource URL through the URL normalizer again, for
+// example after changing URL_TRACKING_PARAMS or URL_STRIP_FRAGMENT, and relinks the resources to
+// their canonical links. Resources that now duplicate another one in the same library are left
+// unlinked and listed so they can be merged or deleted by hand.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"devlink/internal/config"
+	"devlink/internal/db"
+	"devlink/internal/repository"
+	"devlink/internal/urlnorm"
+)
+
+func main() {
+	config.LoadEnv()
+	dbURL := flag.String("db", config.GetEnv("DB_URL", "devlink.db"), "database to update")
+	dryRun := flag.Bool("dry-run", false, "report what would change without saving it")
+	flag.Parse()
+
+	dbConn := db.InitDB(*dbURL)
+	resources := repository.NewResourceRepository(dbConn, urlnorm.NewFromEnv())
+
+	report, err := resources.RenormalizeURLs(*dryRun)
+	if err != nil {
+		log.Fatalf("Failed to renormalize URLs: %v", err)
+	}
+
+	if *dryRun {
+		fmt.Println("Dry run, nothing was saved")
+	}
+	fmt.Printf("Checked %d resource(s): %d URL(s) normalized, %d relinked, %d unused canonical link(s) removed\n",
+		report.Checked, report.Normalized, report.Linked, report.Pruned)
+	if len(report.Invalid) > 0 {
+		fmt.Printf("%d resource(s) have a URL that is not absolute and stay unlinked: %v\n", len(report.Invalid), report.Invalid)
+	}
+	if len(report.Duplicates) > 0 {
+		fmt.Printf("%d duplicate(s) stay unlinked:\n", len(report.Duplicates))
+		for _, duplicate := range report.Duplicates {
+			library := fmt.Sprintf("user %d", duplicate.UserID)
+			if duplicate.WorkspaceID != nil {
+				library = fmt.Sprintf("workspace %d", *duplicate.WorkspaceID)
+			}
+			fmt.Printf("  resource %d duplicates resource %d in %s: %s\n",
+				duplicate.ResourceID, duplicate.ExistingResourceID, library, duplicate.URL)
+		}
+	}
+}
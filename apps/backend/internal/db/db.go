@@ -12,14 +12,53 @@ var DB *gorm.DB
 
 func InitDB(dbURL string) *gorm.DB {
 	var err error
-	DB, err = gorm.Open(sqlite.Open(dbURL), &gorm.Config{})
+	// TranslateError turns unique constraint failures into gorm.ErrDuplicatedKey
+	DB, err = gorm.Open(sqlite.Open(dbURL), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("failed to connect to database: ", err)
 	}
 
-	err = DB.AutoMigrate(&models.User{}, &models.Resource{})
+	err = DB.AutoMigrate(
+		&models.User{},
+		&models.Resource{},
+		&models.CanonicalLink{},
+		&models.RefreshToken{},
+		&models.Session{},
+		&models.RevokedToken{},
+		&models.PersonalAccessToken{},
+		&models.OneTimeToken{},
+		&models.RecoveryCode{},
+		&models.UserIdentity{},
+		&models.OIDCLoginState{},
+		&models.LoginThrottle{},
+		&models.AuditLog{},
+		&models.DeviceAuthorization{},
+		&models.Invitation{},
+		&models.Collection{},
+		&models.CollectionItem{},
+		&models.Folder{},
+		&models.SavedQuery{},
+		&models.ShareLink{},
+		&models.Workspace{},
+		&models.WorkspaceMember{},
+		&models.ResourceGrant{},
+		&models.ResourceTransfer{},
+	)
 	if err != nil {
 		log.Fatal("failed to migrate database: ", err)
 	}
+
+	// URLs used to be unique across the instance; they are now unique per library through canonical links
+	if DB.Migrator().HasIndex(&models.Resource{}, "idx_resources_url") {
+		if err := DB.Migrator().DropIndex(&models.Resource{}, "idx_resources_url"); err != nil {
+			log.Fatal("failed to drop the global resource URL index: ", err)
+		}
+	}
+
+	// Subtree lookups always carry the soft-delete clause, and with only single-column indexes
+	// SQLite prefers deleted_at = NULL over the path range and walks every live folder
+	if err := DB.Exec("CREATE INDEX IF NOT EXISTS idx_folders_live_path ON folders(deleted_at, path)").Error; err != nil {
+		log.Fatal("failed to create the folder path index: ", err)
+	}
 	return DB
 }
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+// AdminUserResponse is the moderation view of an account
+type AdminUserResponse struct {
+	UserResponse
+	MFAEnabled            bool       `json:"mfa_enabled"`
+	SuspendedAt           *time.Time `json:"suspended_at"`
+	SuspendedReason       string     `json:"suspended_reason,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	InvitedByID           *uint      `json:"invited_by_id"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type UpdateRoleRequest struct {
+	Role models.Role `json:"role" validate:"required"`
+}
+
+type AuditLogResponse struct {
+	ID        uint      `json:"id"`
+	UserID    *uint     `json:"user_id"`
+	ActorID   *uint     `json:"actor_id"`
+	Action    string    `json:"action"`
+	IP        string    `json:"ip,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func UserToAdminResponse(user *models.User) AdminUserResponse {
+	return AdminUserResponse{
+		UserResponse:          UserToResponse(user),
+		MFAEnabled:            user.IsMFAEnabled(),
+		SuspendedAt:           user.SuspendedAt,
+		SuspendedReason:       user.SuspendedReason,
+		PasswordResetRequired: user.PasswordResetRequired,
+		InvitedByID:           user.InvitedByID,
+		CreatedAt:             user.CreatedAt,
+	}
+}
+
+func UsersToAdminResponse(users []models.User) []AdminUserResponse {
+	responses := make([]AdminUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = UserToAdminResponse(&user)
+	}
+	return responses
+}
+
+func AuditLogsToResponse(entries []models.AuditLog) []AuditLogResponse {
+	responses := make([]AuditLogResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = AuditLogResponse{
+			ID:        entry.ID,
+			UserID:    entry.UserID,
+			ActorID:   entry.ActorID,
+			Action:    entry.Action,
+			IP:        entry.IP,
+			Details:   entry.Details,
+			CreatedAt: entry.CreatedAt,
+		}
+	}
+	return responses
+}
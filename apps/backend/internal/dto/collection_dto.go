@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+type CreateCollectionRequest struct {
+	WorkspaceID *uint  `json:"workspace_id"`
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Description string `json:"description" validate:"max=500"`
+	Icon        string `json:"icon" validate:"max=32"`
+}
+
+// UpdateCollectionRequest uses pointers so a field can be cleared by sending an empty string
+type UpdateCollectionRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=500"`
+	Icon        *string `json:"icon" validate:"omitempty,max=32"`
+}
+
+type AddCollectionResourceRequest struct {
+	ResourceID uint `json:"resource_id" validate:"required"`
+	Position   *int `json:"position" validate:"omitempty,min=0"`
+}
+
+type ReorderCollectionRequest struct {
+	ResourceIDs []uint `json:"resource_ids" validate:"required"`
+}
+
+type CollectionResponse struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	Icon          string    `json:"icon,omitempty"`
+	ResourceCount int       `json:"resource_count"`
+	UserID        uint      `json:"user_id"`
+	WorkspaceID   *uint     `json:"workspace_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func CollectionToResponse(collection *models.Collection, resourceCount int) CollectionResponse {
+	return CollectionResponse{
+		ID:            collection.ID,
+		Name:          collection.Name,
+		Description:   collection.Description,
+		Icon:          collection.Icon,
+		ResourceCount: resourceCount,
+		UserID:        collection.UserID,
+		WorkspaceID:   collection.WorkspaceID,
+		CreatedAt:     collection.CreatedAt,
+		UpdatedAt:     collection.UpdatedAt,
+	}
+}
+
+func CollectionsToResponse(collections []models.Collection, resourceCounts map[uint]int) []CollectionResponse {
+	responses := make([]CollectionResponse, len(collections))
+	for i, collection := range collections {
+		responses[i] = CollectionToResponse(&collection, resourceCounts[collection.ID])
+	}
+	return responses
+}
@@ -0,0 +1,36 @@
+package dto
+
+// The device authorization endpoints follow the OAuth 2.0 wire format (RFC 8628, RFC 6749)
+// rather than the usual success/data envelope so standard OAuth clients can use them.
+
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type DeviceApprovalRequest struct {
+	UserCode string `json:"user_code" validate:"required"`
+}
+
+// DeviceAuthorizationResponse describes a pending request so the user can check it before approving
+type DeviceAuthorizationResponse struct {
+	UserCode  string `json:"user_code"`
+	ClientID  string `json:"client_id"`
+	ExpiresIn int    `json:"expires_in"`
+}
@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+type CreateFolderRequest struct {
+	Name     string `json:"name" validate:"required,min=1,max=100"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+type RenameFolderRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// MoveFolderRequest moves a folder with everything in it; a null parent_id moves it to the top level
+type MoveFolderRequest struct {
+	ParentID *uint `json:"parent_id"`
+}
+
+type FolderResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  *uint     `json:"parent_id"`
+	Depth     int       `json:"depth"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Breadcrumb is one step on the way from the top of the folder tree to a folder
+type Breadcrumb struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// FolderDetailResponse adds where the folder sits in the tree and what is directly inside it
+type FolderDetailResponse struct {
+	FolderResponse
+	Breadcrumbs []Breadcrumb     `json:"breadcrumbs"`
+	Children    []FolderResponse `json:"children"`
+}
+
+func FolderToResponse(folder *models.Folder) FolderResponse {
+	return FolderResponse{
+		ID:        folder.ID,
+		Name:      folder.Name,
+		ParentID:  folder.ParentID,
+		Depth:     folder.Depth(),
+		CreatedAt: folder.CreatedAt,
+		UpdatedAt: folder.UpdatedAt,
+	}
+}
+
+func FoldersToResponse(folders []models.Folder) []FolderResponse {
+	responses := make([]FolderResponse, len(folders))
+	for i, folder := range folders {
+		responses[i] = FolderToResponse(&folder)
+	}
+	return responses
+}
+
+func FoldersToBreadcrumbs(folders []models.Folder) []Breadcrumb {
+	crumbs := make([]Breadcrumb, len(folders))
+	for i, folder := range folders {
+		crumbs[i] = Breadcrumb{ID: folder.ID, Name: folder.Name}
+	}
+	return crumbs
+}
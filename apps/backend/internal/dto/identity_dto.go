@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+type IdentityResponse struct {
+	ID        uint      `json:"id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func IdentityToResponse(identity *models.UserIdentity) IdentityResponse {
+	return IdentityResponse{
+		ID:        identity.ID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
+
+func IdentitiesToResponse(identities []models.UserIdentity) []IdentityResponse {
+	responses := make([]IdentityResponse, len(identities))
+	for i, identity := range identities {
+		responses[i] = IdentityToResponse(&identity)
+	}
+	return responses
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+type CreateInvitationRequest struct {
+	Email         string `json:"email" validate:"omitempty,email"`
+	MaxUses       int    `json:"max_uses" validate:"omitempty,min=1,max=100"`
+	ExpiresInDays int    `json:"expires_in_days" validate:"omitempty,min=1,max=90"`
+}
+
+type InvitationResponse struct {
+	ID         uint       `json:"id"`
+	InviterID  uint       `json:"inviter_id"`
+	CodePrefix string     `json:"code_prefix"`
+	Email      string     `json:"email,omitempty"`
+	MaxUses    int        `json:"max_uses"`
+	Uses       int        `json:"uses"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedInvitationResponse includes the plain code, which is only ever returned once
+type CreatedInvitationResponse struct {
+	InvitationResponse
+	Code string `json:"code"`
+}
+
+// InvitationDetailResponse lists who has registered with the invitation
+type InvitationDetailResponse struct {
+	InvitationResponse
+	Invitees []PublicUserResponse `json:"invitees"`
+}
+
+func InvitationToResponse(invitation *models.Invitation) InvitationResponse {
+	return InvitationResponse{
+		ID:         invitation.ID,
+		InviterID:  invitation.InviterID,
+		CodePrefix: invitation.CodePrefix,
+		Email:      invitation.Email,
+		MaxUses:    invitation.MaxUses,
+		Uses:       invitation.Uses,
+		ExpiresAt:  invitation.ExpiresAt,
+		RevokedAt:  invitation.RevokedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
+
+func InvitationsToResponse(invitations []models.Invitation) []InvitationResponse {
+	responses := make([]InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = InvitationToResponse(&invitation)
+	}
+	return responses
+}
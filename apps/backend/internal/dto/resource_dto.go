@@ -6,38 +6,80 @@ import (
 )
 
 type ResourceResponse struct {
-	ID          uint                `json:"id"`
-	Title       string              `json:"title"`
-	Type        models.ResourceType `json:"type"`
-	URL         string              `json:"url,omitempty"`
-	Category    models.LinkCategory `json:"category,omitempty"`
-	Description string              `json:"description"`
-	Tags        []string            `json:"tags"`
-	Language    string              `json:"language,omitempty"`
-	CodeContent string              `json:"code_content,omitempty"`
-	UserID      uint                `json:"user_id"`
+	ID          uint                      `json:"id"`
+	Title       string                    `json:"title"`
+	Type        models.ResourceType       `json:"type"`
+	URL         string                    `json:"url,omitempty"`
+	RawURL      string                    `json:"raw_url,omitempty"`
+	Category    models.LinkCategory       `json:"category,omitempty"`
+	Description string                    `json:"description"`
+	Tags        []string                  `json:"tags"`
+	Language    string                    `json:"language,omitempty"`
+	CodeContent string                    `json:"code_content,omitempty"`
+	FolderID    *uint                     `json:"folder_id"`
+	Visibility  models.ResourceVisibility `json:"visibility"`
+	UserID      uint                      `json:"user_id"`
+	WorkspaceID *uint                     `json:"workspace_id"`
+}
+
+// PublicResourceResponse is what readers who do not own the resource see; the owner's
+// folder layout and the URL as they sent it are left out
+type PublicResourceResponse struct {
+	ID          uint                      `json:"id"`
+	Title       string                    `json:"title"`
+	Type        models.ResourceType       `json:"type"`
+	URL         string                    `json:"url,omitempty"`
+	Category    models.LinkCategory       `json:"category,omitempty"`
+	Description string                    `json:"description"`
+	Tags        []string                  `json:"tags"`
+	Language    string                    `json:"language,omitempty"`
+	CodeContent string                    `json:"code_content,omitempty"`
+	Visibility  models.ResourceVisibility `json:"visibility"`
+	UserID      uint                      `json:"user_id"`
 }
 
 type CreateResourceRequest struct {
-	Title       string              `json:"title" validate:"required,min=3,max=100"`
-	Type        models.ResourceType `json:"type" validate:"required,oneof=link code"`
-	URL         string              `json:"url" validate:"omitempty,url"`
-	Category    models.LinkCategory `json:"category" validate:"omitempty,oneof=github article tool other"`
-	Description string              `json:"description" validate:"max=500"`
-	Tags        []string            `json:"tags" validate:"max=10,dive,max=30"`
-	Language    string              `json:"language" validate:"omitempty,min=2,max=20"`
-	CodeContent string              `json:"code_content" validate:"omitempty,min=1,max=10000"`
+	Title       string                    `json:"title" validate:"required,min=3,max=100"`
+	Type        models.ResourceType       `json:"type" validate:"required,oneof=link code"`
+	URL         string                    `json:"url" validate:"omitempty,url"`
+	Category    models.LinkCategory       `json:"category" validate:"omitempty,oneof=github article tool other"`
+	Description string                    `json:"description" validate:"max=500"`
+	Tags        []string                  `json:"tags" validate:"max=10,dive,max=30"`
+	Language    string                    `json:"language" validate:"omitempty,min=2,max=20"`
+	CodeContent string                    `json:"code_content" validate:"omitempty,min=1,max=10000"`
+	FolderID    *uint                     `json:"folder_id"`
+	WorkspaceID *uint                     `json:"workspace_id"`
+	Visibility  models.ResourceVisibility `json:"visibility" validate:"omitempty,oneof=private unlisted public"`
 }
 
 type UpdateResourceRequest struct {
-	Title       string              `json:"title" validate:"omitempty,min=3,max=100"`
-	Type        models.ResourceType `json:"type" validate:"omitempty,oneof=link code"`
-	URL         string              `json:"url" validate:"omitempty,url"`
-	Category    models.LinkCategory `json:"category" validate:"omitempty,oneof=github article tool other"`
-	Description string              `json:"description" validate:"omitempty,max=500"`
-	Tags        []string            `json:"tags" validate:"omitempty,max=10,dive,max=30"`
-	Language    string              `json:"language" validate:"omitempty,min=2,max=20"`
-	CodeContent string              `json:"code_content" validate:"omitempty,min=1,max=10000"`
+	Title       string                    `json:"title" validate:"omitempty,min=3,max=100"`
+	Type        models.ResourceType       `json:"type" validate:"omitempty,oneof=link code"`
+	URL         string                    `json:"url" validate:"omitempty,url"`
+	Category    models.LinkCategory       `json:"category" validate:"omitempty,oneof=github article tool other"`
+	Description string                    `json:"description" validate:"omitempty,max=500"`
+	Tags        []string                  `json:"tags" validate:"omitempty,max=10,dive,max=30"`
+	Language    string                    `json:"language" validate:"omitempty,min=2,max=20"`
+	CodeContent string                    `json:"code_content" validate:"omitempty,min=1,max=10000"`
+	Visibility  models.ResourceVisibility `json:"visibility" validate:"omitempty,oneof=private unlisted public"`
+}
+
+// LinkLookupResponse tells the caller whether a URL is already in their library and how many
+// resources across the instance save it
+type LinkLookupResponse struct {
+	URL        string `json:"url"`
+	SaveCount  int64  `json:"save_count"`
+	ResourceID *uint  `json:"resource_id"`
+}
+
+// DuplicateResourceResponse is the data of a 409 for a link the library already has
+type DuplicateResourceResponse struct {
+	ExistingResourceID uint `json:"existing_resource_id"`
+}
+
+// MoveResourceRequest files a resource in a folder; a null folder_id moves it to the top level
+type MoveResourceRequest struct {
+	FolderID *uint `json:"folder_id"`
 }
 
 func ResourceToResponse(resource *models.Resource) ResourceResponse {
@@ -51,13 +93,59 @@ func ResourceToResponse(resource *models.Resource) ResourceResponse {
 		Title:       resource.Title,
 		Type:        resource.Type,
 		URL:         resource.URL,
+		RawURL:      resource.RawURL,
 		Category:    resource.Category,
 		Description: resource.Description,
 		Tags:        tags,
 		Language:    resource.Language,
 		CodeContent: resource.CodeContent,
+		FolderID:    resource.FolderID,
+		Visibility:  resource.Visibility,
 		UserID:      resource.UserID,
+		WorkspaceID: resource.WorkspaceID,
+	}
+}
+
+// ResourcesToResponseFor converts resources listed for userID. Resources other users shared
+// with them are shown without the owner's folder layout or the URL as the owner sent it.
+func ResourcesToResponseFor(resources []models.Resource, userID uint) []ResourceResponse {
+	responses := ResourcesToResponse(resources)
+	for i := range responses {
+		if responses[i].UserID != userID {
+			responses[i].FolderID = nil
+			responses[i].RawURL = ""
+		}
+	}
+	return responses
+}
+
+func ResourceToPublicResponse(resource *models.Resource) PublicResourceResponse {
+	var tags []string
+	if resource.Tags != nil {
+		json.Unmarshal(resource.Tags, &tags)
 	}
+
+	return PublicResourceResponse{
+		ID:          resource.ID,
+		Title:       resource.Title,
+		Type:        resource.Type,
+		URL:         resource.URL,
+		Category:    resource.Category,
+		Description: resource.Description,
+		Tags:        tags,
+		Language:    resource.Language,
+		CodeContent: resource.CodeContent,
+		Visibility:  resource.Visibility,
+		UserID:      resource.UserID,
+	}
+}
+
+func ResourcesToPublicResponse(resources []models.Resource) []PublicResourceResponse {
+	responses := make([]PublicResourceResponse, len(resources))
+	for i, resource := range resources {
+		responses[i] = ResourceToPublicResponse(&resource)
+	}
+	return responses
 }
 
 func ResourcesToResponse(resources []models.Resource) []ResourceResponse {
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+// GrantResourceRequest names the user by username or email
+type GrantResourceRequest struct {
+	Username   string                 `json:"username"`
+	Email      string                 `json:"email" validate:"omitempty,email"`
+	Permission models.GrantPermission `json:"permission" validate:"required,oneof=read edit"`
+}
+
+type UpdateGrantRequest struct {
+	Permission models.GrantPermission `json:"permission" validate:"required,oneof=read edit"`
+}
+
+type ResourceGrantResponse struct {
+	UserID      uint                   `json:"user_id"`
+	Username    string                 `json:"username"`
+	Permission  models.GrantPermission `json:"permission"`
+	GrantedByID uint                   `json:"granted_by_id"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// SharedResourceResponse is a resource in the "shared with me" list, with what the caller may do
+type SharedResourceResponse struct {
+	PublicResourceResponse
+	Permission models.GrantPermission `json:"permission"`
+	SharedByID uint                   `json:"shared_by_id"`
+}
+
+func ResourceGrantToResponse(grant *models.ResourceGrant, username string) ResourceGrantResponse {
+	return ResourceGrantResponse{
+		UserID:      grant.UserID,
+		Username:    username,
+		Permission:  grant.Permission,
+		GrantedByID: grant.GrantedByID,
+		CreatedAt:   grant.CreatedAt,
+		UpdatedAt:   grant.UpdatedAt,
+	}
+}
+
+func SharedResourceToResponse(resource *models.Resource, permission models.GrantPermission, sharedByID uint) SharedResourceResponse {
+	return SharedResourceResponse{
+		PublicResourceResponse: ResourceToPublicResponse(resource),
+		Permission:             permission,
+		SharedByID:             sharedByID,
+	}
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+type CreateSavedQueryRequest struct {
+	Name          string              `json:"name" validate:"required,min=1,max=100"`
+	Query         string              `json:"query" validate:"max=200"`
+	Tags          []string            `json:"tags" validate:"max=10,dive,max=30"`
+	Type          models.ResourceType `json:"type" validate:"omitempty,oneof=link code"`
+	Category      models.LinkCategory `json:"category" validate:"omitempty,oneof=github article tool other"`
+	Language      string              `json:"language" validate:"omitempty,max=20"`
+	CreatedAfter  *time.Time          `json:"created_after"`
+	CreatedBefore *time.Time          `json:"created_before"`
+	Pinned        bool                `json:"pinned"`
+}
+
+// UpdateSavedQueryRequest uses pointers so a criterion can be cleared by sending an empty value
+type UpdateSavedQueryRequest struct {
+	Name          *string              `json:"name" validate:"omitempty,min=1,max=100"`
+	Query         *string              `json:"query" validate:"omitempty,max=200"`
+	Tags          *[]string            `json:"tags" validate:"omitempty,max=10,dive,max=30"`
+	Type          *models.ResourceType `json:"type" validate:"omitempty,oneof=link code"`
+	Category      *models.LinkCategory `json:"category" validate:"omitempty,oneof=github article tool other"`
+	Language      *string              `json:"language" validate:"omitempty,max=20"`
+	CreatedAfter  *time.Time           `json:"created_after"`
+	CreatedBefore *time.Time           `json:"created_before"`
+	ClearDates    bool                 `json:"clear_dates"`
+	Pinned        *bool                `json:"pinned"`
+}
+
+type SavedQueryResponse struct {
+	ID            uint                `json:"id"`
+	Name          string              `json:"name"`
+	Query         string              `json:"query,omitempty"`
+	Tags          []string            `json:"tags"`
+	Type          models.ResourceType `json:"type,omitempty"`
+	Category      models.LinkCategory `json:"category,omitempty"`
+	Language      string              `json:"language,omitempty"`
+	CreatedAfter  *time.Time          `json:"created_after,omitempty"`
+	CreatedBefore *time.Time          `json:"created_before,omitempty"`
+	Pinned        bool                `json:"pinned"`
+	ResourceCount int64               `json:"resource_count"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+func SavedQueryToResponse(savedQuery *models.SavedQuery, resourceCount int64) SavedQueryResponse {
+	tags := savedQuery.TagList()
+	if tags == nil {
+		tags = []string{}
+	}
+	return SavedQueryResponse{
+		ID:            savedQuery.ID,
+		Name:          savedQuery.Name,
+		Query:         savedQuery.Query,
+		Tags:          tags,
+		Type:          savedQuery.Type,
+		Category:      savedQuery.Category,
+		Language:      savedQuery.Language,
+		CreatedAfter:  savedQuery.CreatedAfter,
+		CreatedBefore: savedQuery.CreatedBefore,
+		Pinned:        savedQuery.PinnedAt != nil,
+		ResourceCount: resourceCount,
+		CreatedAt:     savedQuery.CreatedAt,
+		UpdatedAt:     savedQuery.UpdatedAt,
+	}
+}
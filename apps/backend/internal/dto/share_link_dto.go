@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+// CreateShareLinkRequest sets how long the link works; zero means it never expires
+type CreateShareLinkRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" validate:"omitempty,min=1,max=8760"`
+}
+
+type ShareLinkResponse struct {
+	ID             uint       `json:"id"`
+	ResourceID     uint       `json:"resource_id"`
+	TokenPrefix    string     `json:"token_prefix"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	Active         bool       `json:"active"`
+	Views          int        `json:"views"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CreatedShareLinkResponse includes the plain token, which is only ever returned once
+type CreatedShareLinkResponse struct {
+	ShareLinkResponse
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+func ShareLinkToResponse(link *models.ShareLink) ShareLinkResponse {
+	return ShareLinkResponse{
+		ID:             link.ID,
+		ResourceID:     link.ResourceID,
+		TokenPrefix:    link.TokenPrefix,
+		ExpiresAt:      link.ExpiresAt,
+		RevokedAt:      link.RevokedAt,
+		Active:         link.IsActive(time.Now()),
+		Views:          link.Views,
+		LastAccessedAt: link.LastAccessedAt,
+		CreatedAt:      link.CreatedAt,
+	}
+}
+
+func ShareLinksToResponse(links []models.ShareLink) []ShareLinkResponse {
+	responses := make([]ShareLinkResponse, len(links))
+	for i, link := range links {
+		responses[i] = ShareLinkToResponse(&link)
+	}
+	return responses
+}
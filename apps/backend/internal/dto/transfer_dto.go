@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+// CreateTransferRequest names the recipient by username or email and picks either a selection
+// of personal resources or the whole account
+type CreateTransferRequest struct {
+	Username    string `json:"username"`
+	Email       string `json:"email" validate:"omitempty,email"`
+	ResourceIDs []uint `json:"resource_ids" validate:"omitempty,max=500"`
+	All         bool   `json:"all"`
+	Message     string `json:"message" validate:"max=500"`
+}
+
+// AcceptTransferRequest decides what happens to URLs the recipient already has; fail is the default
+type AcceptTransferRequest struct {
+	OnConflict models.TransferConflictPolicy `json:"on_conflict" validate:"omitempty,oneof=fail skip"`
+}
+
+type TransferResponse struct {
+	ID           uint                      `json:"id"`
+	FromUserID   uint                      `json:"from_user_id"`
+	ToUserID     uint                      `json:"to_user_id"`
+	WholeAccount bool                      `json:"whole_account"`
+	ResourceIDs  []uint                    `json:"resource_ids"`
+	Message      string                    `json:"message"`
+	Status       models.TransferStatus     `json:"status"`
+	Pending      bool                      `json:"pending"`
+	ExpiresAt    time.Time                 `json:"expires_at"`
+	RespondedAt  *time.Time                `json:"responded_at"`
+	MovedCount   int                       `json:"moved_count"`
+	SkippedCount int                       `json:"skipped_count"`
+	Conflicts    []models.TransferConflict `json:"conflicts,omitempty"`
+	CreatedAt    time.Time                 `json:"created_at"`
+}
+
+func TransferToResponse(transfer *models.ResourceTransfer) TransferResponse {
+	resourceIDs := transfer.ResourceIDList()
+	if resourceIDs == nil {
+		resourceIDs = []uint{}
+	}
+	return TransferResponse{
+		ID:           transfer.ID,
+		FromUserID:   transfer.FromUserID,
+		ToUserID:     transfer.ToUserID,
+		WholeAccount: transfer.WholeAccount,
+		ResourceIDs:  resourceIDs,
+		Message:      transfer.Message,
+		Status:       transfer.Status,
+		Pending:      transfer.IsPending(time.Now()),
+		ExpiresAt:    transfer.ExpiresAt,
+		RespondedAt:  transfer.RespondedAt,
+		MovedCount:   transfer.MovedCount,
+		SkippedCount: transfer.SkippedCount,
+		CreatedAt:    transfer.CreatedAt,
+	}
+}
+
+func TransfersToResponse(transfers []models.ResourceTransfer) []TransferResponse {
+	responses := make([]TransferResponse, len(transfers))
+	for i, transfer := range transfers {
+		responses[i] = TransferToResponse(&transfer)
+	}
+	return responses
+}
@@ -3,15 +3,37 @@ package dto
 import "devlink/internal/models"
 
 type UserResponse struct {
+	ID            uint        `json:"id"`
+	Username      string      `json:"username"`
+	Email         string      `json:"email"`
+	EmailVerified bool        `json:"email_verified"`
+	PendingEmail  string      `json:"pending_email,omitempty"`
+	Role          models.Role `json:"role"`
+}
+
+// PublicUserResponse is what other users may see about an account
+type PublicUserResponse struct {
 	ID       uint   `json:"id"`
 	Username string `json:"username"`
-	Email    string `json:"email"`
 }
 
 type RegisterRequest struct {
-	Username string `json:"username" validate:"required,min=3,max=50,alphanum"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Username     string `json:"username" validate:"required,min=3,max=50,alphanum"`
+	Email        string `json:"email" validate:"required,email"`
+	Password     string `json:"password" validate:"required,min=8"`
+	PoWChallenge string `json:"pow_challenge"`
+	PoWNonce     string `json:"pow_nonce"`
+	Invitation   string `json:"invitation_code"`
+}
+
+// RegistrationChallengeResponse is the puzzle a client must solve before registering:
+// find a nonce so that SHA-256(challenge + ":" + nonce) starts with difficulty zero bits
+type RegistrationChallengeResponse struct {
+	Required   bool   `json:"required"`
+	Challenge  string `json:"challenge,omitempty"`
+	Algorithm  string `json:"algorithm,omitempty"`
+	Difficulty int    `json:"difficulty,omitempty"`
+	ExpiresIn  int    `json:"expires_in,omitempty"`
 }
 
 type LoginRequest struct {
@@ -19,10 +41,50 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// AuthResponse carries a new token pair. In cookie session mode the tokens are sent as
+// cookies instead and only the CSRF token is returned.
+type AuthResponse struct {
+	User         UserResponse `json:"user"`
+	Token        string       `json:"token,omitempty"`
+	RefreshToken string       `json:"refresh_token,omitempty"`
+	CSRFToken    string       `json:"csrf_token,omitempty"`
+	ExpiresIn    int          `json:"expires_in"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MagicLinkLoginRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
 type UpdateUserRequest struct {
-	Username string `json:"username" validate:"omitempty,min=3,max=50,alphanum"`
-	Email    string `json:"email" validate:"omitempty,email"`
-	Password string `json:"password" validate:"omitempty,min=8"`
+	Username        string `json:"username" validate:"omitempty,min=3,max=50,alphanum"`
+	Email           string `json:"email" validate:"omitempty,email"`
+	Password        string `json:"password" validate:"omitempty,min=8"`
+	CurrentPassword string `json:"current_password" validate:"required_with=Password"`
 }
 
 type PaginationParams struct {
@@ -32,9 +94,19 @@ type PaginationParams struct {
 
 func UserToResponse(user *models.User) UserResponse {
 	return UserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		PendingEmail:  user.PendingEmail,
+		Role:          user.CurrentRole(),
+	}
+}
+
+func UserToPublicResponse(user *models.User) PublicUserResponse {
+	return PublicUserResponse{
 		ID:       user.ID,
 		Username: user.Username,
-		Email:    user.Email,
 	}
 }
 
@@ -44,4 +116,4 @@ func UsersToResponse(users []models.User) []UserResponse {
 		responses[i] = UserToResponse(&user)
 	}
 	return responses
-}
\ No newline at end of file
+}
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"devlink/internal/models"
+	"time"
+)
+
+type CreateWorkspaceRequest struct {
+	Name        string `json:"name" validate:"required,min=1,max=100"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+// UpdateWorkspaceRequest uses pointers so the description can be cleared by sending an empty string
+type UpdateWorkspaceRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=500"`
+}
+
+// InviteWorkspaceMemberRequest names the user by username or email; the role defaults to viewer
+type InviteWorkspaceMemberRequest struct {
+	Username string               `json:"username"`
+	Email    string               `json:"email" validate:"omitempty,email"`
+	Role     models.WorkspaceRole `json:"role" validate:"omitempty,oneof=owner editor viewer"`
+}
+
+type UpdateWorkspaceMemberRequest struct {
+	Role models.WorkspaceRole `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+// WorkspaceResponse includes the caller's own role in the workspace
+type WorkspaceResponse struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	CreatedByID uint                 `json:"created_by_id"`
+	Role        models.WorkspaceRole `json:"role"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+type WorkspaceMemberResponse struct {
+	UserID      uint                 `json:"user_id"`
+	Username    string               `json:"username"`
+	Role        models.WorkspaceRole `json:"role"`
+	Pending     bool                 `json:"pending"`
+	InvitedByID *uint                `json:"invited_by_id"`
+	AcceptedAt  *time.Time           `json:"accepted_at"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+func WorkspaceToResponse(workspace *models.Workspace, role models.WorkspaceRole) WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:          workspace.ID,
+		Name:        workspace.Name,
+		Description: workspace.Description,
+		CreatedByID: workspace.CreatedByID,
+		Role:        role,
+		CreatedAt:   workspace.CreatedAt,
+		UpdatedAt:   workspace.UpdatedAt,
+	}
+}
+
+func WorkspaceMemberToResponse(member *models.WorkspaceMember, username string) WorkspaceMemberResponse {
+	return WorkspaceMemberResponse{
+		UserID:      member.UserID,
+		Username:    username,
+		Role:        member.Role,
+		Pending:     !member.IsActive(),
+		InvitedByID: member.InvitedByID,
+		AcceptedAt:  member.AcceptedAt,
+		CreatedAt:   member.CreatedAt,
+	}
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/mailer"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type AdminHandler struct {
+	repo          *repository.UserRepository
+	tokens        *repository.TokenRepository
+	accessTokens  *repository.AccessTokenRepository
+	oneTimeTokens *repository.OneTimeTokenRepository
+	audit         *repository.AuditRepository
+	guard         *LoginGuard
+	mailer        mailer.Mailer
+}
+
+func NewAdminHandler(userRepository *repository.UserRepository, tokenRepository *repository.TokenRepository, accessTokenRepository *repository.AccessTokenRepository, oneTimeTokenRepository *repository.OneTimeTokenRepository, auditRepository *repository.AuditRepository, guard *LoginGuard, m mailer.Mailer) *AdminHandler {
+	return &AdminHandler{
+		repo:          userRepository,
+		tokens:        tokenRepository,
+		accessTokens:  accessTokenRepository,
+		oneTimeTokens: oneTimeTokenRepository,
+		audit:         auditRepository,
+		guard:         guard,
+		mailer:        m,
+	}
+}
+
+// ListUsersHandler pages through accounts, optionally filtered by ?q=, ?role= and ?suspended=
+func (h *AdminHandler) ListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := paginationParams(r)
+
+	query := r.URL.Query()
+	filter := repository.UserFilter{
+		Query: strings.TrimSpace(query.Get("q")),
+		Role:  models.Role(query.Get("role")),
+	}
+	if filter.Role != "" && !filter.Role.IsValid() {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRole)
+		return
+	}
+	if raw := query.Get("suspended"); raw != "" {
+		suspended, err := strconv.ParseBool(raw)
+		if err != nil {
+			dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+			return
+		}
+		filter.Suspended = &suspended
+	}
+
+	users, total, err := h.repo.ListUsers(filter, page, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(dto.UsersToAdminResponse(users), "Users retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *AdminHandler) GetUserHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+	dto.WriteSuccess(w, http.StatusOK, dto.UserToAdminResponse(user), "User retrieved successfully")
+}
+
+// SuspendUserHandler blocks an account and signs it out everywhere
+func (h *AdminHandler) SuspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	var suspendReq dto.SuspendUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&suspendReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(suspendReq.Reason) > 500 {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	actor, user, ok := h.moderate(w, r)
+	if !ok {
+		return
+	}
+	if user.IsSuspended() {
+		dto.WriteSuccess(w, http.StatusOK, dto.UserToAdminResponse(user), "User is already suspended")
+		return
+	}
+	if !h.keepsAnAdmin(w, user) {
+		return
+	}
+
+	now := time.Now()
+	user.SuspendedAt = &now
+	user.SuspendedReason = strings.TrimSpace(suspendReq.Reason)
+	if err := h.repo.UpdateUser(user); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := h.tokens.RevokeAllForUser(user.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !h.record(w, r, actor, user, models.AuditActionUserSuspended, user.SuspendedReason) {
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.UserToAdminResponse(user), "User suspended")
+}
+
+func (h *AdminHandler) ReactivateUserHandler(w http.ResponseWriter, r *http.Request) {
+	actor, user, ok := h.moderate(w, r)
+	if !ok {
+		return
+	}
+	if !user.IsSuspended() {
+		dto.WriteSuccess(w, http.StatusOK, dto.UserToAdminResponse(user), "User is not suspended")
+		return
+	}
+
+	user.SuspendedAt = nil
+	user.SuspendedReason = ""
+	if err := h.repo.UpdateUser(user); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !h.record(w, r, actor, user, models.AuditActionUserReactivated, "") {
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.UserToAdminResponse(user), "User reactivated")
+}
+
+// UpdateRoleHandler changes a user's role. Only admins can do this.
+func (h *AdminHandler) UpdateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var roleReq dto.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&roleReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !roleReq.Role.IsValid() {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRole)
+		return
+	}
+
+	actor, user, ok := h.moderate(w, r)
+	if !ok {
+		return
+	}
+	previous := user.CurrentRole()
+	if previous == roleReq.Role {
+		dto.WriteSuccess(w, http.StatusOK, dto.UserToAdminResponse(user), "Role unchanged")
+		return
+	}
+	if !h.keepsAnAdmin(w, user) {
+		return
+	}
+
+	user.Role = roleReq.Role
+	if err := h.repo.UpdateUser(user); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !h.record(w, r, actor, user, models.AuditActionRoleChanged, fmt.Sprintf("%s -> %s", previous, user.Role)) {
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.UserToAdminResponse(user), "Role updated")
+}
+
+// ForcePasswordResetHandler disables the current password, signs the user out everywhere,
+// revokes their access tokens and emails them a reset link
+func (h *AdminHandler) ForcePasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	actor, user, ok := h.moderate(w, r)
+	if !ok {
+		return
+	}
+
+	user.PasswordResetRequired = true
+	if err := h.repo.UpdateUser(user); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := h.tokens.RevokeAllForUser(user.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := h.accessTokens.RevokeAllForUser(user.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ttl := config.GetEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+	token, err := issueOneTimeToken(h.oneTimeTokens, user.ID, models.TokenPurposePasswordReset, user.Email, ttl)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	mailer.SendAsync(h.mailer, mailer.PasswordResetEmail(user.Email, appLink("/reset-password", token), ttl))
+
+	if !h.record(w, r, actor, user, models.AuditActionPasswordReset, "") {
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.UserToAdminResponse(user), "Password reset required. A reset link has been emailed to the user")
+}
+
+// UnlockUserHandler lifts a login lockout without waiting for it to expire
+func (h *AdminHandler) UnlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	actor, user, ok := h.moderate(w, r)
+	if !ok {
+		return
+	}
+	if err := h.guard.Unlock(user, &actor.ID, middleware.ClientIP(r)); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	dto.WriteSuccess(w, http.StatusOK, nil, "User unlocked")
+}
+
+// GetAuditLogHandler returns the security events recorded for a user, newest first
+func (h *AdminHandler) GetAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+	page, pageSize := paginationParams(r)
+
+	entries, total, err := h.audit.GetByUserID(user.ID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(dto.AuditLogsToResponse(entries), "Audit log retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+// targetUser loads the user named by the {id} route parameter
+func (h *AdminHandler) targetUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+	user, err := h.repo.GetByID(uint(userID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, err)
+			return nil, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return user, true
+}
+
+// moderate loads the target user and checks the caller may act on them: nobody acts on
+// their own account here, and moderators can only act on plain users
+func (h *AdminHandler) moderate(w http.ResponseWriter, r *http.Request) (*models.User, *models.User, bool) {
+	actor, ok := middleware.GetCurrentUser(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, nil, false
+	}
+	user, ok := h.targetUser(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	if actor.ID == user.ID || (!actor.HasRole(models.RoleAdmin) && user.HasRole(models.RoleModerator)) {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return nil, nil, false
+	}
+	return actor, user, true
+}
+
+// keepsAnAdmin refuses to take away the last active admin
+func (h *AdminHandler) keepsAnAdmin(w http.ResponseWriter, user *models.User) bool {
+	if !user.HasRole(models.RoleAdmin) || user.IsSuspended() {
+		return true
+	}
+	admins, err := h.repo.CountActiveAdmins()
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	if admins <= 1 {
+		dto.WriteError(w, http.StatusConflict, models.ErrLastAdmin)
+		return false
+	}
+	return true
+}
+
+func (h *AdminHandler) record(w http.ResponseWriter, r *http.Request, actor, user *models.User, action, details string) bool {
+	err := h.audit.Record(&models.AuditLog{
+		UserID:  &user.ID,
+		ActorID: &actor.ID,
+		Action:  action,
+		IP:      middleware.ClientIP(r),
+		Details: details,
+	})
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	return true
+}
@@ -2,22 +2,41 @@ package handlers
 
 import (
 	"devlink/internal/dto"
+	"devlink/internal/mailer"
+	"devlink/internal/middleware"
 	"devlink/internal/models"
+	"devlink/internal/password"
 	"devlink/internal/repository"
 	"devlink/internal/utils"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
-	repo *repository.UserRepository
+	repo          *repository.UserRepository
+	tokens        *repository.TokenRepository
+	oneTimeTokens *repository.OneTimeTokenRepository
+	recoveryCodes *repository.RecoveryCodeRepository
+	invitations   *repository.InvitationRepository
+	guard         *LoginGuard
+	hasher        *password.Hasher
+	mailer        mailer.Mailer
 }
 
-func NewAuthHandler(userRepository *repository.UserRepository) *AuthHandler {
+func NewAuthHandler(userRepository *repository.UserRepository, tokenRepository *repository.TokenRepository, oneTimeTokenRepository *repository.OneTimeTokenRepository, recoveryCodeRepository *repository.RecoveryCodeRepository, invitationRepository *repository.InvitationRepository, guard *LoginGuard, hasher *password.Hasher, m mailer.Mailer) *AuthHandler {
 	return &AuthHandler{
-		repo: userRepository,
+		repo:          userRepository,
+		tokens:        tokenRepository,
+		oneTimeTokens: oneTimeTokenRepository,
+		recoveryCodes: recoveryCodeRepository,
+		invitations:   invitationRepository,
+		guard:         guard,
+		hasher:        hasher,
+		mailer:        m,
 	}
 }
 
@@ -55,31 +74,107 @@ func (h *AuthHandler) RegisterUserHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Invite-only deployments need a code; elsewhere a code is optional and only records who invited whom
+	var invitation *models.Invitation
+	if registerReq.Invitation != "" {
+		var err error
+		if invitation, err = lookupInvitation(h.invitations, registerReq.Invitation, user.Email); err != nil {
+			var validationErr *models.ValidationError
+			if errors.As(err, &validationErr) {
+				dto.WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	} else if InviteOnlyRegistration() {
+		dto.WriteError(w, http.StatusForbidden, models.ErrInvitationRequired)
+		return
+	}
+
+	// Each solved challenge pays for exactly one registration attempt
+	if difficulty := utils.PoWDifficulty(); difficulty > 0 {
+		if !h.consumePoW(registerReq.PoWChallenge, registerReq.PoWNonce, difficulty) {
+			dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidPoWSolution)
+			return
+		}
+	}
+
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	hashedPassword, err := h.hasher.Hash(user.Password)
 	if err != nil {
 		dto.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	user.Password = string(hashedPassword)
+	user.Password = hashedPassword
 
-	// Create user
-	if err := h.repo.CreateUser(&user); err != nil {
+	// Create user, using up one redemption of the invitation
+	if invitation != nil {
+		if err := h.invitations.RedeemAndCreateUser(invitation, &user); err != nil {
+			if errors.Is(err, models.ErrInvalidInvitation) {
+				dto.WriteError(w, http.StatusBadRequest, err)
+				return
+			}
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	} else if err := h.repo.CreateUser(&user); err != nil {
 		dto.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Username)
+	// Ask the user to confirm the address they registered with
+	if err := sendVerificationEmail(h.oneTimeTokens, h.mailer, user.ID, user.Email); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Start a new session for this login
+	auth, err := issueTokens(h.tokens, &user, r)
 	if err != nil {
 		dto.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	dto.WriteSuccess(w, http.StatusCreated, map[string]interface{}{
-		"user":  dto.UserToResponse(&user),
-		"token": token,
-	}, "User registered successfully")
+	writeAuthResponse(w, r, http.StatusCreated, auth, "User registered successfully")
+}
+
+// RegistrationChallengeHandler hands out a proof-of-work puzzle that must be solved to register.
+// Clients can always call it; "required" is false while the gate is turned off.
+func (h *AuthHandler) RegistrationChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	difficulty := utils.PoWDifficulty()
+	if difficulty <= 0 {
+		dto.WriteSuccess(w, http.StatusOK, dto.RegistrationChallengeResponse{Required: false}, "No registration challenge required")
+		return
+	}
+
+	challenge, err := utils.GeneratePoWChallenge(difficulty)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	dto.WriteSuccess(w, http.StatusOK, dto.RegistrationChallengeResponse{
+		Required:   true,
+		Challenge:  challenge,
+		Algorithm:  "sha256",
+		Difficulty: difficulty,
+		ExpiresIn:  int(utils.PoWChallengeTTL().Seconds()),
+	}, "Registration challenge created")
+}
+
+// consumePoW checks a registration solution and burns the challenge so it cannot be replayed
+func (h *AuthHandler) consumePoW(challenge, nonce string, difficulty int) bool {
+	claims, ok := utils.VerifyPoW(challenge, nonce, difficulty)
+	if !ok {
+		return false
+	}
+	jti, _ := claims["jti"].(string)
+	exp, err := claims.GetExpirationTime()
+	if jti == "" || err != nil || exp == nil {
+		return false
+	}
+	claimed, err := h.tokens.ClaimJTI(jti, exp.Time)
+	return err == nil && claimed
 }
 
 func (h *AuthHandler) LoginUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -96,32 +191,322 @@ func (h *AuthHandler) LoginUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Refuse to check the password at all while the account or client is backing off
+	ip := middleware.ClientIP(r)
+	if wait, err := h.guard.Check(loginReq.Email, ip); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if wait > 0 {
+		writeThrottled(w, wait, models.ErrLoginThrottled)
+		return
+	}
+
 	// Get user from database
 	user, err := h.repo.GetByEmail(loginReq.Email)
 	if err != nil {
-		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		h.loginFailed(w, loginReq.Email, ip, models.ErrInvalidCredentials)
 		return
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginReq.Password)); err != nil {
-		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+	// Verify password; accounts created through an identity provider have none
+	valid := false
+	if user.HasPassword() {
+		if valid, err = h.hasher.Verify(loginReq.Password, user.Password); err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	if !valid {
+		h.loginFailed(w, loginReq.Email, ip, models.ErrInvalidCredentials)
+		return
+	}
+
+	// Move the hash to the current algorithm and parameters while we have the plain password
+	if h.hasher.NeedsRehash(user.Password) {
+		if rehashed, err := h.hasher.Hash(loginReq.Password); err == nil {
+			if err := h.repo.UpdatePassword(user.ID, rehashed); err == nil {
+				user.Password = rehashed
+			}
+		}
+	}
+
+	// A second factor is still required, so only reset the counter once the login completes
+	if !user.IsMFAEnabled() {
+		if err := h.guard.RecordSuccess(user.Email); err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	completeLogin(w, r, h.tokens, user)
+}
+
+// LoginMFAHandler exchanges the challenge from LoginUserHandler plus a second factor for tokens
+func (h *AuthHandler) LoginMFAHandler(w http.ResponseWriter, r *http.Request) {
+	var mfaReq dto.LoginMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&mfaReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	claims, err := utils.ParseJWT(mfaReq.MFAToken, utils.TokenTypeMFAChallenge)
+	if err != nil {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidMFAToken)
+		return
+	}
+	jti, _ := claims["jti"].(string)
+	if revoked, err := h.tokens.IsJTIRevoked(jti); err != nil || revoked {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidMFAToken)
+		return
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidMFAToken)
+		return
+	}
+
+	user, err := h.repo.GetByID(uint(userID))
+	if err != nil || !user.IsMFAEnabled() {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidMFAToken)
+		return
+	}
+	if user.IsSuspended() {
+		dto.WriteError(w, http.StatusForbidden, models.ErrAccountSuspended)
+		return
+	}
+	// The reset may have been forced after the challenge was handed out
+	if user.PasswordResetRequired {
+		dto.WriteError(w, http.StatusForbidden, models.ErrPasswordResetRequired)
+		return
+	}
+
+	// Wrong codes count against the account just like wrong passwords
+	ip := middleware.ClientIP(r)
+	if wait, err := h.guard.Check(user.Email, ip); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if wait > 0 {
+		writeThrottled(w, wait, models.ErrLoginThrottled)
+		return
+	}
+
+	valid, err := verifySecondFactor(h.repo, h.recoveryCodes, user, mfaReq.Code)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !valid {
+		h.loginFailed(w, user.Email, ip, models.ErrInvalidMFACode)
+		return
+	}
+	if err := h.guard.RecordSuccess(user.Email); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// A challenge can only be completed once
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidMFAToken)
+		return
+	}
+	if err := h.tokens.RevokeJTI(jti, exp.Time); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Start a new session for this login
+	auth, err := issueTokens(h.tokens, user, r)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeAuthResponse(w, r, http.StatusOK, auth, "Login successful")
+}
+
+// UnlockAccountHandler lifts a lockout using the link emailed when the account was locked
+func (h *AuthHandler) UnlockAccountHandler(w http.ResponseWriter, r *http.Request) {
+	var unlockReq dto.UnlockAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&unlockReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if unlockReq.Token == "" {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	token, err := h.oneTimeTokens.Consume(utils.HashToken(unlockReq.Token), models.TokenPurposeAccountUnlock)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidOneTimeToken) {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err := h.repo.GetByID(token.UserID)
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidOneTimeToken)
+		return
+	}
+
+	if err := h.guard.Unlock(user, nil, middleware.ClientIP(r)); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Account unlocked. You can log in again")
+}
+
+func (h *AuthHandler) RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var refreshReq dto.RefreshRequest
+	if middleware.WantsCookieAuth(r) {
+		// Cookie session mode: the refresh token is in an HttpOnly cookie, so the request
+		// has to carry the CSRF token instead of a body
+		cookie, err := r.Cookie(middleware.RefreshCookie)
+		if err != nil || cookie.Value == "" {
+			dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidRefreshToken)
+			return
+		}
+		if !middleware.ValidCSRF(r) {
+			dto.WriteError(w, http.StatusForbidden, models.ErrInvalidCSRFToken)
+			return
+		}
+		refreshReq.RefreshToken = cookie.Value
+	} else if err := json.NewDecoder(r.Body).Decode(&refreshReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if refreshReq.RefreshToken == "" {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	stored, err := h.tokens.GetRefreshTokenByHash(utils.HashToken(refreshReq.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidRefreshToken)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// A rotated or revoked token being presented again means it was leaked,
+	// so the whole family is revoked and the user has to log in again
+	if stored.RotatedAt != nil || stored.RevokedAt != nil {
+		if err := h.tokens.RevokeFamily(stored.FamilyID); err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidRefreshToken)
+		return
+	}
+	if !stored.IsActive(time.Now()) {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidRefreshToken)
+		return
+	}
+	if ok := h.ensureSession(w, r, stored); !ok {
+		return
+	}
+
+	user, err := h.repo.GetByID(stored.UserID)
+	if err != nil {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidRefreshToken)
+		return
+	}
+	if user.IsSuspended() {
+		dto.WriteError(w, http.StatusForbidden, models.ErrAccountSuspended)
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Username)
+	auth, err := rotateTokens(h.tokens, user, stored)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidRefreshToken) {
+			// Lost a race with a concurrent refresh of the same token; treat it as reuse
+			h.tokens.RevokeFamily(stored.FamilyID)
+			dto.WriteError(w, http.StatusUnauthorized, err)
+			return
+		}
 		dto.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	dto.WriteSuccess(w, http.StatusOK, map[string]interface{}{
-		"user":  dto.UserToResponse(user),
-		"token": token,
-	}, "Login successful")
+	writeAuthResponse(w, r, http.StatusOK, auth, "Token refreshed successfully")
+}
+
+// ensureSession checks the session a refresh token belongs to is still active. Logins from
+// before sessions were tracked get one on their first refresh.
+func (h *AuthHandler) ensureSession(w http.ResponseWriter, r *http.Request, stored *models.RefreshToken) bool {
+	session, err := h.tokens.GetSessionByFamily(stored.FamilyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		session = &models.Session{
+			UserID:     stored.UserID,
+			FamilyID:   stored.FamilyID,
+			UserAgent:  truncate(r.UserAgent(), 255),
+			IP:         middleware.ClientIP(r),
+			LastSeenAt: time.Now(),
+			ExpiresAt:  stored.ExpiresAt,
+		}
+		err = h.tokens.CreateSession(session)
+	}
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	if session.RevokedAt != nil {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidRefreshToken)
+		return false
+	}
+	return true
+}
+
+// loginFailed records a failed attempt before answering with err
+func (h *AuthHandler) loginFailed(w http.ResponseWriter, email, ip string, err error) {
+	if recordErr := h.guard.RecordFailure(email, ip); recordErr != nil {
+		dto.WriteError(w, http.StatusInternalServerError, recordErr)
+		return
+	}
+	dto.WriteError(w, http.StatusUnauthorized, err)
 }
 
 func (h *AuthHandler) LogoutUserHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	if middleware.IsPersonalAccessToken(claims) {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrTokenNotSession)
+		return
+	}
+
+	// Revoke the refresh-token family this session was issued with
+	if familyID, _ := claims["fid"].(string); familyID != "" {
+		if err := h.tokens.RevokeFamily(familyID); err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	// Revoke the access token itself so it stops working immediately
+	jti, _ := claims["jti"].(string)
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	if err := h.tokens.RevokeJTI(jti, exp.Time); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if middleware.HasSessionCookies(r) {
+		middleware.ClearSessionCookies(w)
+	}
+
 	dto.WriteSuccess(w, http.StatusOK, nil, "User logged out successfully")
 }
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"devlink/internal/db"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+)
+
+func TestConsumePoWRejectsReplay(t *testing.T) {
+	const difficulty = 8
+	h := &AuthHandler{tokens: repository.NewTokenRepository(db.InitDB(filepath.Join(t.TempDir(), "test.db")))}
+
+	challenge, err := utils.GeneratePoWChallenge(difficulty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := utils.SolvePoW(challenge, difficulty)
+
+	if !h.consumePoW(challenge, nonce, difficulty) {
+		t.Fatal("first use of a solved challenge was rejected")
+	}
+	if h.consumePoW(challenge, nonce, difficulty) {
+		t.Fatal("a solved challenge was accepted twice")
+	}
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/policy"
+	"devlink/internal/repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type CollectionHandler struct {
+	repo      *repository.CollectionRepository
+	resources *repository.ResourceRepository
+	policy    *policy.Policy
+}
+
+func NewCollectionHandler(collectionRepository *repository.CollectionRepository, resourceRepository *repository.ResourceRepository, resourcePolicy *policy.Policy) *CollectionHandler {
+	return &CollectionHandler{
+		repo:      collectionRepository,
+		resources: resourceRepository,
+		policy:    resourcePolicy,
+	}
+}
+
+func (h *CollectionHandler) CreateCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	var createReq dto.CreateCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	// Workspace collections need an editor
+	if createReq.WorkspaceID != nil {
+		if _, ok := workspaceAccess(w, h.policy, userID, *createReq.WorkspaceID, models.WorkspaceRoleEditor); !ok {
+			return
+		}
+	}
+
+	collection := &models.Collection{
+		UserID:      userID,
+		WorkspaceID: createReq.WorkspaceID,
+		Name:        createReq.Name,
+		Description: createReq.Description,
+		Icon:        createReq.Icon,
+	}
+	if err := collection.Validate(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.repo.CreateCollection(collection); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.CollectionToResponse(collection, 0), "Collection created successfully")
+}
+
+func (h *CollectionHandler) GetCollectionsHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	// List a workspace's collections with ?workspace=<id>
+	page, pageSize := paginationParams(r)
+	var collections []models.Collection
+	var total int64
+	var err error
+	if workspaceParam := r.URL.Query().Get("workspace"); workspaceParam != "" {
+		workspaceID, convErr := strconv.Atoi(workspaceParam)
+		if convErr != nil {
+			dto.WriteError(w, http.StatusBadRequest, convErr)
+			return
+		}
+		if _, ok := workspaceAccess(w, h.policy, userID, uint(workspaceID), models.WorkspaceRoleViewer); !ok {
+			return
+		}
+		collections, total, err = h.repo.GetByWorkspaceID(uint(workspaceID), page, pageSize)
+	} else {
+		collections, total, err = h.repo.GetByUserID(userID, page, pageSize)
+	}
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ids := make([]uint, len(collections))
+	for i, collection := range collections {
+		ids[i] = collection.ID
+	}
+	counts, err := h.repo.CountResources(ids)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(dto.CollectionsToResponse(collections, counts), "Collections retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *CollectionHandler) GetCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.authorizedCollection(w, r, policy.ActionRead)
+	if !ok {
+		return
+	}
+
+	counts, err := h.repo.CountResources([]uint{collection.ID})
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.CollectionToResponse(collection, counts[collection.ID]), "Collection retrieved successfully")
+}
+
+func (h *CollectionHandler) UpdateCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.authorizedCollection(w, r, policy.ActionEdit)
+	if !ok {
+		return
+	}
+
+	var updateReq dto.UpdateCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Update fields if provided
+	if updateReq.Name != nil {
+		collection.Name = *updateReq.Name
+	}
+	if updateReq.Description != nil {
+		collection.Description = *updateReq.Description
+	}
+	if updateReq.Icon != nil {
+		collection.Icon = *updateReq.Icon
+	}
+	if err := collection.Validate(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.repo.UpdateCollection(collection); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	counts, err := h.repo.CountResources([]uint{collection.ID})
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.CollectionToResponse(collection, counts[collection.ID]), "Collection updated successfully")
+}
+
+// DeleteCollectionHandler deletes a collection; the resources in it are kept
+func (h *CollectionHandler) DeleteCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.authorizedCollection(w, r, policy.ActionDelete)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteCollection(collection.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Collection deleted successfully")
+}
+
+// GetCollectionResourcesHandler pages through a collection's resources in their manual order
+func (h *CollectionHandler) GetCollectionResourcesHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.authorizedCollection(w, r, policy.ActionRead)
+	if !ok {
+		return
+	}
+
+	page, pageSize := paginationParams(r)
+	resources, total, err := h.repo.GetResources(collection.ID, page, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(dto.ResourcesToResponse(resources), "Resources retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+// AddCollectionResourceHandler adds a resource from the collection's library, at the end unless a position is given
+func (h *CollectionHandler) AddCollectionResourceHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.authorizedCollection(w, r, policy.ActionEdit)
+	if !ok {
+		return
+	}
+
+	var addReq dto.AddCollectionResourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&addReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if addReq.Position != nil && *addReq.Position < 0 {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	// Personal collections take the owner's personal resources, workspace collections that workspace's
+	resource, err := h.resources.GetByID(addReq.ResourceID)
+	if err != nil || !sameLibrary(collection, resource) {
+		dto.WriteError(w, http.StatusNotFound, models.ErrResourceNotFound)
+		return
+	}
+
+	item, err := h.repo.AddResource(collection.ID, resource.ID, addReq.Position)
+	if err != nil {
+		if errors.Is(err, models.ErrResourceInCollection) {
+			dto.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, item, "Resource added to collection")
+}
+
+func (h *CollectionHandler) RemoveCollectionResourceHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.authorizedCollection(w, r, policy.ActionEdit)
+	if !ok {
+		return
+	}
+
+	resourceID, err := strconv.Atoi(mux.Vars(r)["resourceId"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.repo.RemoveResource(collection.ID, uint(resourceID)); err != nil {
+		if errors.Is(err, models.ErrResourceNotInCollection) {
+			dto.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Resource removed from collection")
+}
+
+// ReorderCollectionHandler replaces the collection's order with the given list of resource IDs
+func (h *CollectionHandler) ReorderCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := h.authorizedCollection(w, r, policy.ActionEdit)
+	if !ok {
+		return
+	}
+
+	var reorderReq dto.ReorderCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&reorderReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.repo.Reorder(collection.ID, reorderReq.ResourceIDs); err != nil {
+		if errors.Is(err, models.ErrInvalidCollectionOrder) {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Collection reordered successfully")
+}
+
+// authorizedCollection loads the collection in the route and checks the caller may perform action on it
+func (h *CollectionHandler) authorizedCollection(w http.ResponseWriter, r *http.Request, action policy.Action) (*models.Collection, bool) {
+	collectionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	collection, err := h.repo.GetByID(uint(collectionID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrCollectionNotFound)
+			return nil, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, false
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	allowed, err := h.policy.CanAccessCollection(userID, collection, action)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if !allowed {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return nil, false
+	}
+	return collection, true
+}
+
+// sameLibrary reports whether the resource belongs to the library the collection is in
+func sameLibrary(collection *models.Collection, resource *models.Resource) bool {
+	if collection.WorkspaceID != nil {
+		return resource.WorkspaceID != nil && *resource.WorkspaceID == *collection.WorkspaceID
+	}
+	return resource.WorkspaceID == nil && resource.UserID == collection.UserID
+}
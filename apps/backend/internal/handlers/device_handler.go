@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// userCodeAlphabet leaves out vowels and easily confused characters (RFC 8628 section 6.1)
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+const userCodeLength = 8
+
+// DeviceHandler implements the OAuth 2.0 device authorization grant so command-line tools
+// can log in without handling the user's password
+type DeviceHandler struct {
+	devices *repository.DeviceAuthorizationRepository
+	users   *repository.UserRepository
+	tokens  *repository.TokenRepository
+}
+
+func NewDeviceHandler(deviceRepository *repository.DeviceAuthorizationRepository, userRepository *repository.UserRepository, tokenRepository *repository.TokenRepository) *DeviceHandler {
+	return &DeviceHandler{
+		devices: deviceRepository,
+		users:   userRepository,
+		tokens:  tokenRepository,
+	}
+}
+
+// DeviceCodeHandler starts a device login and returns the codes the device shows to the user
+func (h *DeviceHandler) DeviceCodeHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Malformed form body")
+		return
+	}
+	clientID := strings.TrimSpace(r.PostForm.Get("client_id"))
+	if clientID == "" || len(clientID) > 100 {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "client_id is required")
+		return
+	}
+
+	deviceCode, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+	userCode, err := generateUserCode()
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	ttl := config.GetEnvDuration("DEVICE_CODE_TTL", 10*time.Minute)
+	authorization := &models.DeviceAuthorization{
+		DeviceCodeHash: utils.HashToken(deviceCode),
+		UserCode:       userCode,
+		ClientID:       clientID,
+		Interval:       config.GetEnvInt("DEVICE_POLL_INTERVAL", 5),
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+	if err := h.devices.Create(authorization); err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	verificationURI := appURL("/device")
+	dto.WriteJSON(w, http.StatusOK, dto.DeviceCodeResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                formatUserCode(userCode),
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(formatUserCode(userCode)),
+		ExpiresIn:               int(ttl.Seconds()),
+		Interval:                authorization.Interval,
+	})
+}
+
+// GetDeviceAuthorizationHandler shows which client is asking so the user can check before approving
+func (h *DeviceHandler) GetDeviceAuthorizationHandler(w http.ResponseWriter, r *http.Request) {
+	authorization, ok := h.pendingAuthorization(w, r.URL.Query().Get("user_code"))
+	if !ok {
+		return
+	}
+	dto.WriteSuccess(w, http.StatusOK, dto.DeviceAuthorizationResponse{
+		UserCode:  formatUserCode(authorization.UserCode),
+		ClientID:  authorization.ClientID,
+		ExpiresIn: int(time.Until(authorization.ExpiresAt).Seconds()),
+	}, "Device authorization retrieved successfully")
+}
+
+// ApproveDeviceHandler lets the logged-in user grant the waiting device a session
+func (h *DeviceHandler) ApproveDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, true)
+}
+
+// DenyDeviceHandler rejects the waiting device
+func (h *DeviceHandler) DenyDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, false)
+}
+
+func (h *DeviceHandler) decide(w http.ResponseWriter, r *http.Request, approve bool) {
+	var approvalReq dto.DeviceApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&approvalReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	// A script holding a personal access token must not be able to mint sessions
+	if middleware.IsPersonalAccessToken(claims) {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrTokenNotSession)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	authorization, ok := h.pendingAuthorization(w, approvalReq.UserCode)
+	if !ok {
+		return
+	}
+	decided, err := h.devices.Decide(authorization.ID, userID, approve)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !decided {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidUserCode)
+		return
+	}
+
+	if approve {
+		dto.WriteSuccess(w, http.StatusOK, nil, "Device approved. You can return to your terminal")
+		return
+	}
+	dto.WriteSuccess(w, http.StatusOK, nil, "Device request denied")
+}
+
+// TokenHandler is polled by the device until the user has approved or denied the request
+func (h *DeviceHandler) TokenHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Malformed form body")
+		return
+	}
+	if r.PostForm.Get("grant_type") != deviceCodeGrantType {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+		return
+	}
+	deviceCode := r.PostForm.Get("device_code")
+	if deviceCode == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "device_code is required")
+		return
+	}
+
+	authorization, err := h.devices.GetByDeviceCodeHash(utils.HashToken(deviceCode))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Unknown device code")
+			return
+		}
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+	if authorization.ClientID != r.PostForm.Get("client_id") {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "The device code was issued to another client")
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case authorization.ConsumedAt != nil:
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "The device code has already been used")
+		return
+	case authorization.DeniedAt != nil:
+		writeOAuthError(w, http.StatusBadRequest, "access_denied", "")
+		return
+	case !now.Before(authorization.ExpiresAt):
+		writeOAuthError(w, http.StatusBadRequest, "expired_token", "")
+		return
+	case authorization.ApprovedAt == nil:
+		// Devices polling faster than the agreed interval have to back off by 5 seconds each time
+		interval := authorization.Interval
+		tooSoon := authorization.LastPolledAt != nil && now.Before(authorization.LastPolledAt.Add(time.Duration(interval)*time.Second))
+		if tooSoon {
+			interval += 5
+		}
+		if err := h.devices.RecordPoll(authorization.ID, now, interval); err != nil {
+			writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+			return
+		}
+		if tooSoon {
+			writeOAuthError(w, http.StatusBadRequest, "slow_down", "")
+			return
+		}
+		writeOAuthError(w, http.StatusBadRequest, "authorization_pending", "")
+		return
+	}
+
+	consumed, err := h.devices.Consume(authorization.ID)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+	if !consumed {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "The device code has already been used")
+		return
+	}
+
+	// The account may have been suspended or sent to a password reset since it approved the device
+	user, err := h.users.GetByID(*authorization.UserID)
+	if err != nil || user.IsSuspended() {
+		writeOAuthError(w, http.StatusBadRequest, "access_denied", "")
+		return
+	}
+	if user.PasswordResetRequired {
+		writeOAuthError(w, http.StatusBadRequest, "access_denied", models.ErrPasswordResetRequired.Message)
+		return
+	}
+
+	// The device gets its own session, like any other login
+	auth, err := issueTokens(h.tokens, user, r)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	dto.WriteJSON(w, http.StatusOK, dto.OAuthTokenResponse{
+		AccessToken:  auth.Token,
+		TokenType:    "Bearer",
+		ExpiresIn:    auth.ExpiresIn,
+		RefreshToken: auth.RefreshToken,
+	})
+}
+
+func (h *DeviceHandler) pendingAuthorization(w http.ResponseWriter, userCode string) (*models.DeviceAuthorization, bool) {
+	code := normalizeUserCode(userCode)
+	if len(code) != userCodeLength {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidUserCode)
+		return nil, false
+	}
+	authorization, err := h.devices.GetPendingByUserCode(code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrInvalidUserCode)
+			return nil, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return authorization, true
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	dto.WriteJSON(w, status, dto.OAuthErrorResponse{Error: code, ErrorDescription: description})
+}
+
+func generateUserCode() (string, error) {
+	max := big.NewInt(int64(len(userCodeAlphabet)))
+	code := make([]byte, userCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// formatUserCode splits the code in two halves for reading, e.g. BCDF-GHJK
+func formatUserCode(code string) string {
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+// normalizeUserCode accepts the code typed in any case, with or without the dash
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if strings.ContainsRune(userCodeAlphabet, r) {
+			return r
+		}
+		return -1
+	}, code)
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type FolderHandler struct {
+	repo *repository.FolderRepository
+}
+
+func NewFolderHandler(folderRepository *repository.FolderRepository) *FolderHandler {
+	return &FolderHandler{
+		repo: folderRepository,
+	}
+}
+
+func (h *FolderHandler) CreateFolderHandler(w http.ResponseWriter, r *http.Request) {
+	var createReq dto.CreateFolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	name, err := models.ValidateFolderName(createReq.Name)
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	parent, ok := userFolder(w, h.repo, userID, createReq.ParentID)
+	if !ok {
+		return
+	}
+	if parent != nil && parent.Depth() >= models.MaxFolderDepth {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrFolderTooDeep)
+		return
+	}
+	if !h.nameAvailable(w, userID, createReq.ParentID, name, 0) {
+		return
+	}
+
+	folder := &models.Folder{
+		UserID:   userID,
+		ParentID: createReq.ParentID,
+		Name:     name,
+	}
+	if err := h.repo.CreateFolder(folder, parent); err != nil {
+		writeFolderSaveError(w, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.FolderToResponse(folder), "Folder created successfully")
+}
+
+// GetFoldersHandler lists the folders inside ?parent_id= (the top level by default),
+// or every folder of the user with ?tree=true
+func (h *FolderHandler) GetFoldersHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	query := r.URL.Query()
+	var folders []models.Folder
+	var err error
+	if tree, _ := strconv.ParseBool(query.Get("tree")); tree {
+		folders, err = h.repo.GetTree(userID)
+	} else {
+		var parentID *uint
+		if raw := query.Get("parent_id"); raw != "" {
+			id, convErr := strconv.Atoi(raw)
+			if convErr != nil {
+				dto.WriteError(w, http.StatusBadRequest, convErr)
+				return
+			}
+			parentID = new(uint)
+			*parentID = uint(id)
+			if _, ok := userFolder(w, h.repo, userID, parentID); !ok {
+				return
+			}
+		}
+		folders, err = h.repo.GetChildren(userID, parentID)
+	}
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.FoldersToResponse(folders), "Folders retrieved successfully")
+}
+
+// GetFolderHandler returns a folder with its breadcrumbs and subfolders
+func (h *FolderHandler) GetFolderHandler(w http.ResponseWriter, r *http.Request) {
+	folder, ok := h.ownedFolder(w, r)
+	if !ok {
+		return
+	}
+	h.writeFolderDetail(w, folder, "Folder retrieved successfully")
+}
+
+// LookupFolderHandler finds a folder by its path of names, e.g. ?path=Work/Go/Tools
+func (h *FolderHandler) LookupFolderHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	path := strings.Trim(r.URL.Query().Get("path"), "/")
+	if path == "" {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+	segments := strings.Split(path, "/")
+	if len(segments) > models.MaxFolderDepth {
+		dto.WriteError(w, http.StatusNotFound, models.ErrFolderNotFound)
+		return
+	}
+
+	var folder *models.Folder
+	var parentID *uint
+	for _, segment := range segments {
+		var err error
+		folder, err = h.repo.GetChildByName(userID, parentID, strings.TrimSpace(segment))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				dto.WriteError(w, http.StatusNotFound, models.ErrFolderNotFound)
+				return
+			}
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		parentID = &folder.ID
+	}
+
+	h.writeFolderDetail(w, folder, "Folder retrieved successfully")
+}
+
+func (h *FolderHandler) RenameFolderHandler(w http.ResponseWriter, r *http.Request) {
+	folder, ok := h.ownedFolder(w, r)
+	if !ok {
+		return
+	}
+
+	var renameReq dto.RenameFolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&renameReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	name, err := models.ValidateFolderName(renameReq.Name)
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !h.nameAvailable(w, folder.UserID, folder.ParentID, name, folder.ID) {
+		return
+	}
+
+	if err := h.repo.RenameFolder(folder.ID, name); err != nil {
+		writeFolderSaveError(w, err)
+		return
+	}
+	folder.Name = name
+
+	dto.WriteSuccess(w, http.StatusOK, dto.FolderToResponse(folder), "Folder renamed successfully")
+}
+
+// MoveFolderHandler moves a folder, with everything inside it, under another folder or to the top level
+func (h *FolderHandler) MoveFolderHandler(w http.ResponseWriter, r *http.Request) {
+	folder, ok := h.ownedFolder(w, r)
+	if !ok {
+		return
+	}
+
+	var moveReq dto.MoveFolderRequest
+	if err := json.NewDecoder(r.Body).Decode(&moveReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	parent, ok := userFolder(w, h.repo, folder.UserID, moveReq.ParentID)
+	if !ok {
+		return
+	}
+	if parent != nil && folder.IsAncestorOf(parent) {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrFolderCycle)
+		return
+	}
+
+	// The deepest folder in the subtree must still fit under the new parent
+	subtreeDepth, err := h.repo.SubtreeDepth(folder)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	parentDepth := 0
+	if parent != nil {
+		parentDepth = parent.Depth()
+	}
+	if parentDepth+subtreeDepth > models.MaxFolderDepth {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrFolderTooDeep)
+		return
+	}
+	if !h.nameAvailable(w, folder.UserID, moveReq.ParentID, folder.Name, folder.ID) {
+		return
+	}
+
+	if err := h.repo.MoveFolder(folder, parent); err != nil {
+		writeFolderSaveError(w, err)
+		return
+	}
+
+	h.writeFolderDetail(w, folder, "Folder moved successfully")
+}
+
+// DeleteFolderHandler deletes a folder and all of its subfolders. The resources inside are moved
+// to the top level, or trashed with ?resources=trash.
+func (h *FolderHandler) DeleteFolderHandler(w http.ResponseWriter, r *http.Request) {
+	folder, ok := h.ownedFolder(w, r)
+	if !ok {
+		return
+	}
+
+	var trashResources bool
+	switch r.URL.Query().Get("resources") {
+	case "", "root":
+	case "trash":
+		trashResources = true
+	default:
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.repo.DeleteFolder(folder, trashResources); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Folder deleted successfully")
+}
+
+func (h *FolderHandler) writeFolderDetail(w http.ResponseWriter, folder *models.Folder, message string) {
+	crumbs, err := h.repo.GetBreadcrumbs(folder)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	children, err := h.repo.GetChildren(folder.UserID, &folder.ID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.FolderDetailResponse{
+		FolderResponse: dto.FolderToResponse(folder),
+		Breadcrumbs:    dto.FoldersToBreadcrumbs(crumbs),
+		Children:       dto.FoldersToResponse(children),
+	}, message)
+}
+
+// nameAvailable checks that no other folder next to the target uses the name, like a file system would
+func (h *FolderHandler) nameAvailable(w http.ResponseWriter, userID uint, parentID *uint, name string, exceptID uint) bool {
+	existing, err := h.repo.GetChildByName(userID, parentID, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	if existing.ID == exceptID {
+		return true
+	}
+	dto.WriteError(w, http.StatusConflict, models.ErrFolderNameTaken)
+	return false
+}
+
+// writeFolderSaveError answers a failed create, rename or move, with 409 when another request
+// took the name first
+func writeFolderSaveError(w http.ResponseWriter, err error) {
+	if errors.Is(err, models.ErrFolderNameTaken) {
+		dto.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	dto.WriteError(w, http.StatusInternalServerError, err)
+}
+
+// ownedFolder loads the folder in the route if it belongs to the caller
+func (h *FolderHandler) ownedFolder(w http.ResponseWriter, r *http.Request) (*models.Folder, bool) {
+	folderID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, false
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	id := uint(folderID)
+	return userFolder(w, h.repo, userID, &id)
+}
+
+// userFolder loads a folder of the user by ID. A nil ID stands for the top level and yields a nil folder.
+// Other users' folders are reported as missing.
+func userFolder(w http.ResponseWriter, folders *repository.FolderRepository, userID uint, folderID *uint) (*models.Folder, bool) {
+	if folderID == nil {
+		return nil, true
+	}
+	folder, err := folders.GetByID(*folderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrFolderNotFound)
+			return nil, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if folder.UserID != userID {
+		dto.WriteError(w, http.StatusNotFound, models.ErrFolderNotFound)
+		return nil, false
+	}
+	return folder, true
+}
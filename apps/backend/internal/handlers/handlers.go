@@ -1,17 +1,61 @@
 package handlers
 
-import "devlink/internal/repository"
+import (
+	"devlink/internal/mailer"
+	"devlink/internal/oidc"
+	"devlink/internal/password"
+	"devlink/internal/policy"
+	"devlink/internal/repository"
+	"devlink/internal/urlnorm"
+)
 
 type HandlersContainer struct {
-	UserHandler     *UserHandler
-	AuthHandler     *AuthHandler
-	ResourceHandler *ResourceHandler
+	UserHandler          *UserHandler
+	AuthHandler          *AuthHandler
+	ResourceHandler      *ResourceHandler
+	TokenHandler         *TokenHandler
+	PasswordHandler      *PasswordHandler
+	VerificationHandler  *VerificationHandler
+	MFAHandler           *MFAHandler
+	OIDCHandler          *OIDCHandler
+	AdminHandler         *AdminHandler
+	SessionHandler       *SessionHandler
+	DeviceHandler        *DeviceHandler
+	MagicLinkHandler     *MagicLinkHandler
+	InvitationHandler    *InvitationHandler
+	CollectionHandler    *CollectionHandler
+	FolderHandler        *FolderHandler
+	SavedQueryHandler    *SavedQueryHandler
+	ShareLinkHandler     *ShareLinkHandler
+	WorkspaceHandler     *WorkspaceHandler
+	ResourceGrantHandler *ResourceGrantHandler
+	TransferHandler      *TransferHandler
 }
 
-func NewHandlersContainer(userRepository *repository.UserRepository, resourceRepository *repository.ResourceRepository) *HandlersContainer {
+func NewHandlersContainer(repos *repository.Repositories, m mailer.Mailer, providers map[string]*oidc.Provider, hasher *password.Hasher, urls *urlnorm.Normalizer) *HandlersContainer {
+	loginGuard := NewLoginGuard(repos.Throttle, repos.Audit, repos.User, repos.OneTimeToken, m)
+	resourcePolicy := policy.NewPolicy(repos.Workspace, repos.ResourceGrant)
+
 	return &HandlersContainer{
-		UserHandler:     NewUserHandler(userRepository),
-		AuthHandler:     NewAuthHandler(userRepository),
-		ResourceHandler: NewResourceHandler(resourceRepository),
+		UserHandler:          NewUserHandler(repos.User, repos.OneTimeToken, repos.Token, hasher, m),
+		AuthHandler:          NewAuthHandler(repos.User, repos.Token, repos.OneTimeToken, repos.RecoveryCode, repos.Invitation, loginGuard, hasher, m),
+		ResourceHandler:      NewResourceHandler(repos.Resource, repos.User, repos.Folder, resourcePolicy, urls),
+		TokenHandler:         NewTokenHandler(repos.AccessToken),
+		PasswordHandler:      NewPasswordHandler(repos.User, repos.OneTimeToken, repos.Token, hasher, m),
+		VerificationHandler:  NewVerificationHandler(repos.User, repos.OneTimeToken, m),
+		MFAHandler:           NewMFAHandler(repos.User, repos.RecoveryCode),
+		OIDCHandler:          NewOIDCHandler(providers, repos.User, repos.Identity, repos.Token),
+		AdminHandler:         NewAdminHandler(repos.User, repos.Token, repos.AccessToken, repos.OneTimeToken, repos.Audit, loginGuard, m),
+		SessionHandler:       NewSessionHandler(repos.Token),
+		DeviceHandler:        NewDeviceHandler(repos.Device, repos.User, repos.Token),
+		MagicLinkHandler:     NewMagicLinkHandler(repos.User, repos.Token, repos.OneTimeToken, loginGuard, m),
+		InvitationHandler:    NewInvitationHandler(repos.Invitation, m),
+		CollectionHandler:    NewCollectionHandler(repos.Collection, repos.Resource, resourcePolicy),
+		FolderHandler:        NewFolderHandler(repos.Folder),
+		SavedQueryHandler:    NewSavedQueryHandler(repos.SavedQuery, repos.Resource),
+		ShareLinkHandler:     NewShareLinkHandler(repos.ShareLink, repos.Resource, resourcePolicy),
+		WorkspaceHandler:     NewWorkspaceHandler(repos.Workspace, repos.User, resourcePolicy, m),
+		ResourceGrantHandler: NewResourceGrantHandler(repos.ResourceGrant, repos.Resource, repos.User, resourcePolicy),
+		TransferHandler:      NewTransferHandler(repos.Transfer, repos.User, m),
 	}
 }
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/mailer"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+const defaultInvitationExpiryDays = 7
+
+// InviteOnlyRegistration reports whether new accounts need an invitation (REGISTRATION_MODE=invite)
+func InviteOnlyRegistration() bool {
+	return strings.EqualFold(config.GetEnv("REGISTRATION_MODE", "open"), "invite")
+}
+
+// InvitationCreatorRole is the lowest role allowed to invite people (INVITATION_CREATOR_ROLE)
+func InvitationCreatorRole() models.Role {
+	role := models.Role(strings.ToLower(config.GetEnv("INVITATION_CREATOR_ROLE", string(models.RoleUser))))
+	if !role.IsValid() {
+		return models.RoleAdmin
+	}
+	return role
+}
+
+type InvitationHandler struct {
+	repo   *repository.InvitationRepository
+	mailer mailer.Mailer
+}
+
+func NewInvitationHandler(invitationRepository *repository.InvitationRepository, m mailer.Mailer) *InvitationHandler {
+	return &InvitationHandler{
+		repo:   invitationRepository,
+		mailer: m,
+	}
+}
+
+// CreateInvitationHandler creates an invitation code. Codes bound to an email are also sent there.
+func (h *InvitationHandler) CreateInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	var createReq dto.CreateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, ok := middleware.GetCurrentUser(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+
+	// Validate request
+	createReq.Email = strings.TrimSpace(createReq.Email)
+	if createReq.Email != "" {
+		userTemp := models.User{Email: createReq.Email}
+		if err := userTemp.ValidateEmail(); err != nil {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if createReq.MaxUses == 0 {
+		createReq.MaxUses = 1
+	}
+	if createReq.MaxUses < 1 || createReq.MaxUses > 100 {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidInvitationUses)
+		return
+	}
+	if createReq.ExpiresInDays == 0 {
+		createReq.ExpiresInDays = defaultInvitationExpiryDays
+	}
+	if createReq.ExpiresInDays < 1 || createReq.ExpiresInDays > 90 {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidInvitationExpiry)
+		return
+	}
+
+	// Generate the code; only its hash is persisted
+	secret, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	code := models.InvitationCodePrefix + secret
+
+	invitation := &models.Invitation{
+		InviterID:  user.ID,
+		CodeHash:   utils.HashToken(code),
+		CodePrefix: code[:len(models.InvitationCodePrefix)+6],
+		Email:      createReq.Email,
+		MaxUses:    createReq.MaxUses,
+		ExpiresAt:  time.Now().AddDate(0, 0, createReq.ExpiresInDays),
+	}
+	if err := h.repo.Create(invitation); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if invitation.Email != "" {
+		link := appURL("/register") + "?invitation=" + url.QueryEscape(code)
+		mailer.SendAsync(h.mailer, mailer.InvitationEmail(invitation.Email, user.Username, link, invitation.ExpiresAt))
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.CreatedInvitationResponse{
+		InvitationResponse: dto.InvitationToResponse(invitation),
+		Code:               code,
+	}, "Invitation created successfully. Copy the code now, it will not be shown again")
+}
+
+// GetInvitationsHandler lists the caller's invitations; admins can pass ?all=true to see everyone's
+func (h *InvitationHandler) GetInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetCurrentUser(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+
+	inviterID := &user.ID
+	if all, _ := strconv.ParseBool(r.URL.Query().Get("all")); all {
+		if !user.HasRole(models.RoleAdmin) {
+			dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+			return
+		}
+		inviterID = nil
+	}
+
+	page, pageSize := paginationParams(r)
+	invitations, total, err := h.repo.List(inviterID, page, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(dto.InvitationsToResponse(invitations), "Invitations retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetInvitationHandler returns an invitation together with the users who registered with it
+func (h *InvitationHandler) GetInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	invitation, ok := h.ownedInvitation(w, r)
+	if !ok {
+		return
+	}
+
+	invitees, err := h.repo.GetInvitees(invitation.ID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response := dto.InvitationDetailResponse{
+		InvitationResponse: dto.InvitationToResponse(invitation),
+		Invitees:           make([]dto.PublicUserResponse, len(invitees)),
+	}
+	for i, invitee := range invitees {
+		response.Invitees[i] = dto.UserToPublicResponse(&invitee)
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, response, "Invitation retrieved successfully")
+}
+
+// RevokeInvitationHandler stops an invitation from admitting anyone else
+func (h *InvitationHandler) RevokeInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	invitation, ok := h.ownedInvitation(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Revoke(invitation.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Invitation revoked successfully")
+}
+
+// ownedInvitation loads the invitation in the route if the caller created it or is an admin
+func (h *InvitationHandler) ownedInvitation(w http.ResponseWriter, r *http.Request) (*models.Invitation, bool) {
+	invitationID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	user, ok := middleware.GetCurrentUser(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, false
+	}
+
+	invitation, err := h.repo.GetByID(uint(invitationID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrInvitationNotFound)
+			return nil, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	// Other people's invitations look the same as missing ones
+	if invitation.InviterID != user.ID && !user.HasRole(models.RoleAdmin) {
+		dto.WriteError(w, http.StatusNotFound, models.ErrInvitationNotFound)
+		return nil, false
+	}
+	return invitation, true
+}
+
+// lookupInvitation finds a usable invitation for a registration by the given email
+func lookupInvitation(invitations *repository.InvitationRepository, code, email string) (*models.Invitation, error) {
+	invitation, err := invitations.GetByCodeHash(utils.HashToken(strings.TrimSpace(code)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	if !invitation.IsUsable(time.Now()) {
+		return nil, models.ErrInvalidInvitation
+	}
+	if !invitation.AllowsEmail(email) {
+		return nil, models.ErrInvitationEmailMismatch
+	}
+	return invitation, nil
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+// completeLogin finishes a successful first-factor login: users with two-factor authentication
+// get a short-lived challenge, everyone else gets tokens straight away
+func completeLogin(w http.ResponseWriter, r *http.Request, tokens *repository.TokenRepository, user *models.User) {
+	if user.IsSuspended() {
+		dto.WriteError(w, http.StatusForbidden, models.ErrAccountSuspended)
+		return
+	}
+	// An admin asked for a new password. Until it is reset no way in works, including the
+	// ones that never ask for the old password (identity providers, magic links).
+	if user.PasswordResetRequired {
+		dto.WriteError(w, http.StatusForbidden, models.ErrPasswordResetRequired)
+		return
+	}
+
+	if user.IsMFAEnabled() {
+		challenge, err := utils.GenerateMFAChallenge(user.ID)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		dto.WriteSuccess(w, http.StatusOK, dto.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    challenge,
+			ExpiresIn:   int(utils.MFAChallengeTTL().Seconds()),
+		}, "Two-factor authentication required")
+		return
+	}
+
+	// Start a new session for this login
+	auth, err := issueTokens(tokens, user, r)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeAuthResponse(w, r, http.StatusOK, auth, "Login successful")
+}
+
+// writeAuthResponse sends freshly issued tokens. Clients in cookie session mode get them as
+// HttpOnly cookies instead of in the body, together with a new CSRF token.
+func writeAuthResponse(w http.ResponseWriter, r *http.Request, status int, auth *dto.AuthResponse, message string) {
+	if middleware.WantsCookieAuth(r) {
+		csrfToken, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		middleware.SetSessionCookies(w, auth.Token, auth.RefreshToken, csrfToken)
+		auth.Token = ""
+		auth.RefreshToken = ""
+		auth.CSRFToken = csrfToken
+	}
+	dto.WriteSuccess(w, status, auth, message)
+}
+
+// issueTokens creates an access token and a refresh token for the user, starting a new
+// session (token family) for the device that made the request, as happens on every fresh login
+func issueTokens(tokens *repository.TokenRepository, user *models.User, r *http.Request) (*dto.AuthResponse, error) {
+	refreshToken, stored, err := newRefreshToken(user.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &models.Session{
+		UserID:     user.ID,
+		FamilyID:   stored.FamilyID,
+		UserAgent:  truncate(r.UserAgent(), 255),
+		IP:         middleware.ClientIP(r),
+		LastSeenAt: now,
+		ExpiresAt:  stored.ExpiresAt,
+	}
+	if err := tokens.StartSession(session, stored); err != nil {
+		return nil, err
+	}
+	return buildAuthResponse(user, stored.FamilyID, refreshToken)
+}
+
+// rotateTokens replaces a refresh token with a new one from the same family
+func rotateTokens(tokens *repository.TokenRepository, user *models.User, old *models.RefreshToken) (*dto.AuthResponse, error) {
+	refreshToken, stored, err := newRefreshToken(user.ID, old.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tokens.RotateRefreshToken(old, stored); err != nil {
+		return nil, err
+	}
+	return buildAuthResponse(user, stored.FamilyID, refreshToken)
+}
+
+func newRefreshToken(userID uint, familyID string) (string, *models.RefreshToken, error) {
+	if familyID == "" {
+		var err error
+		if familyID, err = utils.GenerateRandomToken(16); err != nil {
+			return "", nil, err
+		}
+	}
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	return refreshToken, &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL()),
+	}, nil
+}
+
+func buildAuthResponse(user *models.User, familyID, refreshToken string) (*dto.AuthResponse, error) {
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Username, familyID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AuthResponse{
+		User:         dto.UserToResponse(user),
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(utils.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/mailer"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// throttlePolicy says when failures for one kind of key start slowing down and then lock out
+type throttlePolicy struct {
+	freeAttempts int // failures allowed before backoff starts
+	threshold    int // failures that trigger a lockout
+}
+
+// LoginGuard tracks failed logins per account and per client IP. Repeated failures are
+// answered with an exponentially growing delay and eventually a temporary lockout.
+type LoginGuard struct {
+	throttles       *repository.LoginThrottleRepository
+	audit           *repository.AuditRepository
+	users           *repository.UserRepository
+	oneTimeTokens   *repository.OneTimeTokenRepository
+	mailer          mailer.Mailer
+	account         throttlePolicy
+	ip              throttlePolicy
+	baseDelay       time.Duration
+	maxDelay        time.Duration
+	lockoutDuration time.Duration
+	failureWindow   time.Duration
+	magicLinkLimit  int
+	magicLinkWindow time.Duration
+}
+
+func NewLoginGuard(throttleRepository *repository.LoginThrottleRepository, auditRepository *repository.AuditRepository, userRepository *repository.UserRepository, oneTimeTokenRepository *repository.OneTimeTokenRepository, m mailer.Mailer) *LoginGuard {
+	return &LoginGuard{
+		throttles:     throttleRepository,
+		audit:         auditRepository,
+		users:         userRepository,
+		oneTimeTokens: oneTimeTokenRepository,
+		mailer:        m,
+		account: throttlePolicy{
+			freeAttempts: config.GetEnvInt("LOGIN_ACCOUNT_FREE_ATTEMPTS", 3),
+			threshold:    config.GetEnvInt("LOGIN_ACCOUNT_LOCKOUT_THRESHOLD", 10),
+		},
+		// Many users can share one address behind a NAT, so IPs get more headroom
+		ip: throttlePolicy{
+			freeAttempts: config.GetEnvInt("LOGIN_IP_FREE_ATTEMPTS", 20),
+			threshold:    config.GetEnvInt("LOGIN_IP_LOCKOUT_THRESHOLD", 100),
+		},
+		baseDelay:       config.GetEnvDuration("LOGIN_BACKOFF_BASE", time.Second),
+		maxDelay:        config.GetEnvDuration("LOGIN_BACKOFF_MAX", 5*time.Minute),
+		lockoutDuration: config.GetEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		failureWindow:   config.GetEnvDuration("LOGIN_FAILURE_WINDOW", time.Hour),
+		magicLinkLimit:  config.GetEnvInt("MAGIC_LINK_MAX_REQUESTS", 3),
+		magicLinkWindow: config.GetEnvDuration("MAGIC_LINK_WINDOW", time.Hour),
+	}
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+func magicLinkThrottleKey(email string) string {
+	return "magic:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// Check returns how long the client has to wait before it may try to log in to email again.
+// Unknown emails are tracked the same way so lockouts do not reveal which accounts exist.
+func (g *LoginGuard) Check(email, ip string) (time.Duration, error) {
+	throttles, err := g.throttles.GetByKeys(accountThrottleKey(email), ipThrottleKey(ip))
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for _, throttle := range throttles {
+		policy := g.account
+		if strings.HasPrefix(throttle.Key, "ip:") {
+			policy = g.ip
+		}
+
+		var until time.Time
+		switch {
+		case throttle.IsLocked(now):
+			until = *throttle.LockedUntil
+		case throttle.LockedUntil == nil && now.Sub(throttle.LastFailureAt) <= g.failureWindow:
+			until = throttle.LastFailureAt.Add(g.delay(policy, throttle.Failures))
+		}
+		if d := until.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait, nil
+}
+
+// RecordFailure counts a failed attempt against both the account and the client IP,
+// locking either out once it reaches its threshold
+func (g *LoginGuard) RecordFailure(email, ip string) error {
+	now := time.Now()
+
+	account, err := g.throttles.RecordFailure(accountThrottleKey(email), now, g.failureWindow)
+	if err != nil {
+		return err
+	}
+	if account.Failures >= g.account.threshold {
+		if err := g.lockAccount(account, email, ip, now); err != nil {
+			return err
+		}
+	}
+
+	client, err := g.throttles.RecordFailure(ipThrottleKey(ip), now, g.failureWindow)
+	if err != nil {
+		return err
+	}
+	if client.Failures >= g.ip.threshold {
+		until := now.Add(g.lockoutDuration)
+		if err := g.throttles.Lock(client.Key, until); err != nil {
+			return err
+		}
+		return g.audit.Record(&models.AuditLog{
+			Action:  models.AuditActionIPLocked,
+			IP:      ip,
+			Details: fmt.Sprintf("%d failed login attempts, locked until %s", client.Failures, until.UTC().Format(time.RFC3339)),
+		})
+	}
+	return nil
+}
+
+// RecordSuccess forgets the account's failures. The IP's failures are kept so a valid
+// login to one account does not reset guessing against others.
+func (g *LoginGuard) RecordSuccess(email string) error {
+	return g.throttles.Clear(accountThrottleKey(email))
+}
+
+// RecordMagicLinkRequest counts a sign-in link sent to email and returns how long the client has
+// to wait when the address has already had too many. Unknown emails are counted too.
+func (g *LoginGuard) RecordMagicLinkRequest(email string) (time.Duration, error) {
+	key := magicLinkThrottleKey(email)
+	throttles, err := g.throttles.GetByKeys(key)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	if len(throttles) > 0 && throttles[0].IsLocked(now) {
+		return throttles[0].LockedUntil.Sub(now), nil
+	}
+
+	throttle, err := g.throttles.RecordFailure(key, now, g.magicLinkWindow)
+	if err != nil {
+		return 0, err
+	}
+	// This request still goes through; the next ones wait until the window has passed
+	if throttle.Failures >= g.magicLinkLimit {
+		if err := g.throttles.Lock(key, now.Add(g.magicLinkWindow)); err != nil {
+			return 0, err
+		}
+	}
+	return 0, nil
+}
+
+// Unlock lifts an account lockout. actorID is the admin who did it, or nil when the user
+// followed the emailed unlock link.
+func (g *LoginGuard) Unlock(user *models.User, actorID *uint, ip string) error {
+	if err := g.throttles.Clear(accountThrottleKey(user.Email)); err != nil {
+		return err
+	}
+	return g.audit.Record(&models.AuditLog{
+		UserID:  &user.ID,
+		ActorID: actorID,
+		Action:  models.AuditActionAccountUnlocked,
+		IP:      ip,
+	})
+}
+
+func (g *LoginGuard) lockAccount(throttle *models.LoginThrottle, email, ip string, now time.Time) error {
+	until := now.Add(g.lockoutDuration)
+	if err := g.throttles.Lock(throttle.Key, until); err != nil {
+		return err
+	}
+
+	entry := &models.AuditLog{
+		Action:  models.AuditActionAccountLocked,
+		IP:      ip,
+		Details: fmt.Sprintf("%d failed login attempts, locked until %s", throttle.Failures, until.UTC().Format(time.RFC3339)),
+	}
+	user, err := g.users.GetByEmail(email)
+	if err != nil {
+		// Nobody to notify; the lockout still applies so it looks the same as for a real account
+		entry.Details += " (no such account)"
+		return g.audit.Record(entry)
+	}
+	entry.UserID = &user.ID
+	if err := g.audit.Record(entry); err != nil {
+		return err
+	}
+
+	// The link is only useful while the lockout lasts
+	token, err := issueOneTimeToken(g.oneTimeTokens, user.ID, models.TokenPurposeAccountUnlock, user.Email, g.lockoutDuration)
+	if err != nil {
+		log.Printf("Failed to issue unlock token for user %d: %v", user.ID, err)
+		return nil
+	}
+	mailer.SendAsync(g.mailer, mailer.AccountLockedEmail(user.Email, appLink("/unlock-account", token), until))
+	return nil
+}
+
+// delay is the wait imposed after the given number of consecutive failures
+func (g *LoginGuard) delay(policy throttlePolicy, failures int) time.Duration {
+	if failures < policy.freeAttempts {
+		return 0
+	}
+	exponent := failures - policy.freeAttempts
+	if exponent > 30 {
+		return g.maxDelay
+	}
+	d := g.baseDelay * time.Duration(1<<exponent)
+	if d > g.maxDelay || d <= 0 {
+		return g.maxDelay
+	}
+	return d
+}
+
+// writeThrottled answers a throttled request with 429 and the number of seconds to wait
+func writeThrottled(w http.ResponseWriter, wait time.Duration, err error) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	dto.WriteError(w, http.StatusTooManyRequests, err)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/mailer"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MagicLinkHandler signs users in with a single-use link emailed to them instead of a password
+type MagicLinkHandler struct {
+	repo          *repository.UserRepository
+	tokens        *repository.TokenRepository
+	oneTimeTokens *repository.OneTimeTokenRepository
+	guard         *LoginGuard
+	mailer        mailer.Mailer
+}
+
+func NewMagicLinkHandler(userRepository *repository.UserRepository, tokenRepository *repository.TokenRepository, oneTimeTokenRepository *repository.OneTimeTokenRepository, guard *LoginGuard, m mailer.Mailer) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		repo:          userRepository,
+		tokens:        tokenRepository,
+		oneTimeTokens: oneTimeTokenRepository,
+		guard:         guard,
+		mailer:        m,
+	}
+}
+
+// RequestMagicLinkHandler emails a sign-in link. It answers the same way whether or not
+// the account exists so it cannot be used to discover registered emails.
+func (h *MagicLinkHandler) RequestMagicLinkHandler(w http.ResponseWriter, r *http.Request) {
+	var magicReq dto.MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&magicReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validate email format
+	userTemp := models.User{Email: strings.TrimSpace(magicReq.Email)}
+	if err := userTemp.ValidateEmail(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Limit how many links one address can receive, counted before the account lookup
+	if wait, err := h.guard.RecordMagicLinkRequest(userTemp.Email); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	} else if wait > 0 {
+		writeThrottled(w, wait, models.ErrMagicLinkThrottled)
+		return
+	}
+
+	const message = "If an account exists for that email, a sign-in link has been sent"
+
+	user, err := h.repo.GetByEmail(userTemp.Email)
+	if err != nil || user.IsSuspended() {
+		dto.WriteSuccess(w, http.StatusOK, nil, message)
+		return
+	}
+
+	ttl := config.GetEnvDuration("MAGIC_LINK_TTL", 15*time.Minute)
+	token, err := issueOneTimeToken(h.oneTimeTokens, user.ID, models.TokenPurposeMagicLogin, user.Email, ttl)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	link := appLink("/login/magic", token)
+	mailer.SendAsync(h.mailer, mailer.MagicLinkEmail(user.Email, link, ttl))
+
+	dto.WriteSuccess(w, http.StatusOK, nil, message)
+}
+
+// MagicLinkLoginHandler consumes a sign-in link and logs the user in exactly like a password login
+func (h *MagicLinkHandler) MagicLinkLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var loginReq dto.MagicLinkLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if loginReq.Token == "" {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	token, err := h.oneTimeTokens.Consume(utils.HashToken(loginReq.Token), models.TokenPurposeMagicLogin)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidOneTimeToken) {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// A link sent to an address the account no longer uses must not sign anyone in
+	user, err := h.repo.GetByID(token.UserID)
+	if err != nil || !strings.EqualFold(user.Email, token.Email) {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidOneTimeToken)
+		return
+	}
+
+	// Following the emailed link proves the user controls the address
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := h.repo.UpdateUser(user); err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	// Users with two-factor authentication still have to pass their second factor
+	if !user.IsMFAEnabled() {
+		if err := h.guard.RecordSuccess(user.Email); err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	completeLogin(w, r, h.tokens, user)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"devlink/internal/db"
+	"devlink/internal/mailer"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/urlnorm"
+)
+
+var magicLinkToken = regexp.MustCompile(`/login/magic\?token=(\S+)`)
+
+func TestMagicLinkLoginThroughFileMailer(t *testing.T) {
+	repos := repository.NewRepositories(db.InitDB(filepath.Join(t.TempDir(), "test.db")), urlnorm.NewFromEnv())
+	mailDir := t.TempDir()
+	m := mailer.NewFileMailer(mailDir, "DevLink <no-reply@devlink.test>")
+	guard := NewLoginGuard(repos.Throttle, repos.Audit, repos.User, repos.OneTimeToken, m)
+	h := NewMagicLinkHandler(repos.User, repos.Token, repos.OneTimeToken, guard, m)
+
+	user := &models.User{Username: "magic", Email: "magic@example.com"}
+	if err := repos.User.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.RequestMagicLinkHandler(rec, httptest.NewRequest(http.MethodPost, "/users/login/magic", strings.NewReader(`{"email":"magic@example.com"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("requesting a link: status %d: %s", rec.Code, rec.Body)
+	}
+
+	email := waitForEmail(t, mailDir)
+	if !strings.Contains(email, "To: magic@example.com\r\n") {
+		t.Fatalf("email not addressed to the user:\n%s", email)
+	}
+	match := magicLinkToken.FindStringSubmatch(email)
+	if match == nil {
+		t.Fatalf("no sign-in link in the email:\n%s", email)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"token":"` + token + `"}`
+	rec = httptest.NewRecorder()
+	h.MagicLinkLoginHandler(rec, httptest.NewRequest(http.MethodPost, "/users/login/magic/verify", strings.NewReader(body)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"refresh_token"`) {
+		t.Fatalf("signing in with the link: status %d: %s", rec.Code, rec.Body)
+	}
+
+	// Links work once
+	rec = httptest.NewRecorder()
+	h.MagicLinkLoginHandler(rec, httptest.NewRequest(http.MethodPost, "/users/login/magic/verify", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("reusing the link: status %d, want 400", rec.Code)
+	}
+}
+
+// waitForEmail returns the first message the file mailer writes to dir; mail is sent in the background
+func waitForEmail(t *testing.T, dir string) string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) > 0 {
+			content, err := os.ReadFile(files[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(content)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("no email was written")
+	return ""
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+type MFAHandler struct {
+	repo          *repository.UserRepository
+	recoveryCodes *repository.RecoveryCodeRepository
+}
+
+func NewMFAHandler(userRepository *repository.UserRepository, recoveryCodeRepository *repository.RecoveryCodeRepository) *MFAHandler {
+	return &MFAHandler{
+		repo:          userRepository,
+		recoveryCodes: recoveryCodeRepository,
+	}
+}
+
+// EnrollTOTPHandler generates a new secret. It only takes effect once confirmed with a code.
+func (h *MFAHandler) EnrollTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	if user.IsMFAEnabled() {
+		dto.WriteError(w, http.StatusConflict, models.ErrMFAAlreadyEnabled)
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+
+	if err := h.repo.UpdateUser(user); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	issuer := config.GetEnv("MFA_ISSUER", "DevLink")
+	dto.WriteSuccess(w, http.StatusOK, dto.TOTPEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(issuer, user.Email, secret),
+	}, "Scan the code with your authenticator app, then confirm with a code")
+}
+
+// ConfirmTOTPHandler enables two-factor authentication and returns the one-time recovery codes
+func (h *MFAHandler) ConfirmTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	var codeReq dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&codeReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	if user.IsMFAEnabled() {
+		dto.WriteError(w, http.StatusConflict, models.ErrMFAAlreadyEnabled)
+		return
+	}
+	if user.TOTPSecret == "" {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrMFANotEnrolled)
+		return
+	}
+
+	step, valid := utils.ValidateTOTP(user.TOTPSecret, codeReq.Code, time.Now())
+	if !valid {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidMFACode)
+		return
+	}
+
+	codes, err := h.generateRecoveryCodes(user.ID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	user.TOTPLastStep = step
+	if err := h.repo.UpdateUser(user); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes},
+		"Two-factor authentication enabled. Store these recovery codes somewhere safe, they will not be shown again")
+}
+
+// DisableTOTPHandler turns two-factor authentication off after checking a current code
+func (h *MFAHandler) DisableTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	var codeReq dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&codeReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	if !user.IsMFAEnabled() {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrMFANotEnabled)
+		return
+	}
+
+	valid, err := verifySecondFactor(h.repo, h.recoveryCodes, user, codeReq.Code)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !valid {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidMFACode)
+		return
+	}
+
+	if err := h.recoveryCodes.DeleteForUser(user.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	// Reload so the step recorded by verifySecondFactor is not overwritten
+	user, err = h.repo.GetByID(user.ID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = 0
+	if err := h.repo.UpdateUser(user); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Two-factor authentication disabled")
+}
+
+// RegenerateRecoveryCodesHandler replaces all recovery codes after checking a current code
+func (h *MFAHandler) RegenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	var codeReq dto.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&codeReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	if !user.IsMFAEnabled() {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrMFANotEnabled)
+		return
+	}
+
+	valid, err := verifySecondFactor(h.repo, h.recoveryCodes, user, codeReq.Code)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !valid {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidMFACode)
+		return
+	}
+
+	codes, err := h.generateRecoveryCodes(user.ID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes}, "Recovery codes regenerated")
+}
+
+func (h *MFAHandler) currentUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, false
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	user, err := h.repo.GetByID(userID)
+	if err != nil {
+		dto.WriteError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	return user, true
+}
+
+// generateRecoveryCodes stores a fresh set of hashed codes and returns the plain values
+func (h *MFAHandler) generateRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw, err := utils.GenerateTOTPSecret()
+		if err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(raw[:5] + "-" + raw[5:10])
+		codes[i] = code
+		hashes[i] = utils.HashToken(normalizeRecoveryCode(code))
+	}
+	if err := h.recoveryCodes.ReplaceForUser(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery code
+func verifySecondFactor(users *repository.UserRepository, recoveryCodes *repository.RecoveryCodeRepository, user *models.User, code string) (bool, error) {
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		return users.AdvanceTOTPStep(user.ID, step)
+	}
+	return recoveryCodes.Consume(user.ID, utils.HashToken(normalizeRecoveryCode(code)))
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devlink/internal/db"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/urlnorm"
+	"devlink/internal/utils"
+)
+
+func TestVerifySecondFactorRejectsReplayedTOTP(t *testing.T) {
+	repos := repository.NewRepositories(db.InitDB(filepath.Join(t.TempDir(), "test.db")), urlnorm.NewFromEnv())
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	current := utils.TOTPStep(now)
+	// Enrollment was confirmed with the previous step's code
+	user := &models.User{Username: "mfa_user", Email: "mfa@example.com", TOTPSecret: secret, TOTPEnabledAt: &now, TOTPLastStep: current - 1}
+	if err := repos.User.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	codeAt := func(step int64) string {
+		code, err := utils.TOTPCode(secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+	verify := func(code string) bool {
+		t.Helper()
+		ok, err := verifySecondFactor(repos.User, repos.RecoveryCode, user, code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	if verify(codeAt(current - 1)) {
+		t.Fatal("the code used to confirm enrollment was accepted again")
+	}
+	if !verify(codeAt(current)) {
+		t.Fatal("a fresh code was rejected")
+	}
+	if verify(codeAt(current)) {
+		t.Fatal("a code was accepted twice")
+	}
+
+	stored, err := repos.User.GetByID(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.TOTPLastStep < current {
+		t.Fatalf("TOTPLastStep = %d, want at least %d", stored.TOTPLastStep, current)
+	}
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/oidc"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+var usernameUnsafeChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+type OIDCHandler struct {
+	providers  map[string]*oidc.Provider
+	repo       *repository.UserRepository
+	identities *repository.IdentityRepository
+	tokens     *repository.TokenRepository
+}
+
+func NewOIDCHandler(providers map[string]*oidc.Provider, userRepository *repository.UserRepository, identityRepository *repository.IdentityRepository, tokenRepository *repository.TokenRepository) *OIDCHandler {
+	return &OIDCHandler{
+		providers:  providers,
+		repo:       userRepository,
+		identities: identityRepository,
+		tokens:     tokenRepository,
+	}
+}
+
+// ListProvidersHandler returns the names of the configured identity providers
+func (h *OIDCHandler) ListProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(h.providers))
+	for name := range h.providers {
+		names = append(names, name)
+	}
+	dto.WriteSuccess(w, http.StatusOK, names, "Providers retrieved successfully")
+}
+
+// LoginHandler redirects the browser to the provider's authorization endpoint
+func (h *OIDCHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers[mux.Vars(r)["provider"]]
+	if !ok {
+		dto.WriteError(w, http.StatusNotFound, models.ErrUnknownProvider)
+		return
+	}
+
+	authURL, err := h.startFlow(r, provider, nil)
+	if err != nil {
+		log.Printf("OIDC login with %s failed: %v", provider.Name(), err)
+		dto.WriteError(w, http.StatusBadGateway, models.ErrOIDCLoginFailed)
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// LinkIdentityHandler starts a flow that links a provider account to the logged-in user.
+// It returns the authorization URL because the browser cannot send a bearer token on a redirect.
+func (h *OIDCHandler) LinkIdentityHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers[mux.Vars(r)["provider"]]
+	if !ok {
+		dto.WriteError(w, http.StatusNotFound, models.ErrUnknownProvider)
+		return
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	authURL, err := h.startFlow(r, provider, &userID)
+	if err != nil {
+		log.Printf("OIDC link with %s failed: %v", provider.Name(), err)
+		dto.WriteError(w, http.StatusBadGateway, models.ErrOIDCLoginFailed)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, map[string]string{"authorization_url": authURL}, "Open the authorization URL to link your account")
+}
+
+// CallbackHandler finishes the authorization code flow, then either logs the user in or
+// links the identity to the user who started the flow
+func (h *OIDCHandler) CallbackHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers[mux.Vars(r)["provider"]]
+	if !ok {
+		dto.WriteError(w, http.StatusNotFound, models.ErrUnknownProvider)
+		return
+	}
+
+	query := r.URL.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		dto.WriteError(w, http.StatusBadRequest, &models.ValidationError{
+			Message: fmt.Sprintf("Identity provider returned %s: %s", errCode, query.Get("error_description")),
+		})
+		return
+	}
+	code, stateParam := query.Get("code"), query.Get("state")
+	if code == "" || stateParam == "" {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	state, err := h.identities.ConsumeLoginState(utils.HashToken(stateParam), provider.Name())
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidOIDCState) {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	tokenResp, err := provider.Exchange(r.Context(), code, state.CodeVerifier)
+	if err != nil {
+		log.Printf("OIDC code exchange with %s failed: %v", provider.Name(), err)
+		dto.WriteError(w, http.StatusBadGateway, models.ErrOIDCLoginFailed)
+		return
+	}
+	idClaims, err := provider.VerifyIDToken(r.Context(), tokenResp.IDToken, state.Nonce)
+	if err != nil {
+		log.Printf("OIDC ID token from %s rejected: %v", provider.Name(), err)
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrOIDCLoginFailed)
+		return
+	}
+
+	if state.LinkUserID != nil {
+		h.finishLink(w, provider.Name(), *state.LinkUserID, idClaims)
+		return
+	}
+
+	user, status, err := h.resolveUser(provider.Name(), idClaims)
+	if err != nil {
+		dto.WriteError(w, status, err)
+		return
+	}
+
+	completeLogin(w, r, h.tokens, user)
+}
+
+// GetIdentitiesHandler lists the provider accounts linked to the current user
+func (h *OIDCHandler) GetIdentitiesHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	identities, err := h.identities.GetByUserID(userID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.IdentitiesToResponse(identities), "Identities retrieved successfully")
+}
+
+// UnlinkIdentityHandler removes a linked provider account, unless it is the only way left to sign in
+func (h *OIDCHandler) UnlinkIdentityHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	identityID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	identity, err := h.identities.GetByID(uint(identityID))
+	if err != nil {
+		dto.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	// Check if user owns the identity
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+	if identity.UserID != userID {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return
+	}
+
+	user, err := h.repo.GetByID(userID)
+	if err != nil {
+		dto.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	if !user.HasPassword() {
+		identities, err := h.identities.GetByUserID(userID)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if len(identities) <= 1 {
+			dto.WriteError(w, http.StatusConflict, models.ErrLastLoginMethod)
+			return
+		}
+	}
+
+	if err := h.identities.DeleteIdentity(identity.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Identity unlinked successfully")
+}
+
+// startFlow stores a single-use state with its nonce and PKCE verifier and returns the authorization URL
+func (h *OIDCHandler) startFlow(r *http.Request, provider *oidc.Provider, linkUserID *uint) (string, error) {
+	stateValue, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	state := &models.OIDCLoginState{
+		StateHash:    utils.HashToken(stateValue),
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(config.GetEnvDuration("OIDC_STATE_TTL", 10*time.Minute)),
+	}
+	if err := h.identities.CreateLoginState(state); err != nil {
+		return "", err
+	}
+
+	return provider.AuthCodeURL(r.Context(), stateValue, nonce, oidc.CodeChallengeS256(verifier))
+}
+
+func (h *OIDCHandler) finishLink(w http.ResponseWriter, providerName string, userID uint, idClaims *oidc.IDTokenClaims) {
+	existing, err := h.identities.GetByProviderSubject(providerName, idClaims.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			dto.WriteError(w, http.StatusConflict, models.ErrIdentityLinked)
+			return
+		}
+		dto.WriteSuccess(w, http.StatusOK, dto.IdentityToResponse(existing), "Identity already linked")
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	identity := &models.UserIdentity{
+		UserID:   userID,
+		Provider: providerName,
+		Subject:  idClaims.Subject,
+		Email:    idClaims.Email,
+	}
+	if err := h.identities.CreateIdentity(identity); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.IdentityToResponse(identity), "Identity linked successfully")
+}
+
+// resolveUser finds the user for a provider identity, linking by verified email or creating a new account
+func (h *OIDCHandler) resolveUser(providerName string, idClaims *oidc.IDTokenClaims) (*models.User, int, error) {
+	identity, err := h.identities.GetByProviderSubject(providerName, idClaims.Subject)
+	if err == nil {
+		user, err := h.repo.GetByID(identity.UserID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return user, http.StatusOK, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	email := strings.TrimSpace(idClaims.Email)
+	if email == "" {
+		return nil, http.StatusBadRequest, models.ErrOIDCEmailRequired
+	}
+	identity = &models.UserIdentity{
+		Provider: providerName,
+		Subject:  idClaims.Subject,
+		Email:    email,
+	}
+
+	// Only link to an existing account when both sides vouch for the address. Without the
+	// provider's word anyone could claim an account by registering its email at the provider;
+	// without ours, someone could sign up with the victim's address and a password of their own
+	// and wait for the victim to sign in through the provider.
+	if existingUser, _ := h.repo.GetByEmail(email); existingUser != nil {
+		if !idClaims.EmailVerified || !existingUser.IsEmailVerified() {
+			return nil, http.StatusConflict, models.ErrOIDCEmailUnverified
+		}
+		identity.UserID = existingUser.ID
+		if err := h.identities.CreateIdentity(identity); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return existingUser, http.StatusOK, nil
+	}
+
+	// Invitation codes can only be redeemed through the registration form
+	if InviteOnlyRegistration() {
+		return nil, http.StatusForbidden, models.ErrInvitationRequired
+	}
+
+	username, err := h.availableUsername(idClaims)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	user := &models.User{
+		Username: username,
+		Email:    email,
+	}
+	if idClaims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := user.ValidateEmail(); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if err := h.identities.CreateUserWithIdentity(user, identity); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return user, http.StatusCreated, nil
+}
+
+// availableUsername derives a valid, unused username from the provider's profile claims
+func (h *OIDCHandler) availableUsername(idClaims *oidc.IDTokenClaims) (string, error) {
+	base := idClaims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(idClaims.Email, "@")
+	}
+	base = strings.Trim(usernameUnsafeChars.ReplaceAllString(base, "_"), "_")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if len(base) < 3 {
+		base = "user_" + base
+	}
+
+	candidate := base
+	for i := 0; i < 10; i++ {
+		if _, err := h.repo.GetByUsername(candidate); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return candidate, nil
+			}
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s_%04d", base, rand.IntN(10000))
+	}
+	return "", models.ErrOIDCLoginFailed
+}
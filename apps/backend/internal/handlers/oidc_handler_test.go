@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devlink/internal/db"
+	"devlink/internal/models"
+	"devlink/internal/oidc"
+	"devlink/internal/oidc/oidctest"
+	"devlink/internal/repository"
+	"devlink/internal/urlnorm"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+// oidcTest wires an OIDCHandler to a mock identity provider registered as "mock"
+type oidcTest struct {
+	t       *testing.T
+	idp     *oidctest.Server
+	repos   *repository.Repositories
+	handler *OIDCHandler
+}
+
+func newOIDCTest(t *testing.T) *oidcTest {
+	t.Helper()
+	idp := oidctest.NewServer(t)
+	idp.AddKey("k1")
+	provider := oidc.NewProvider(oidc.Config{
+		Name:         "mock",
+		Issuer:       idp.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  "http://localhost/auth/oidc/mock/callback",
+	}, nil)
+	repos := repository.NewRepositories(db.InitDB(filepath.Join(t.TempDir(), "test.db")), urlnorm.NewFromEnv())
+	return &oidcTest{
+		t:       t,
+		idp:     idp,
+		repos:   repos,
+		handler: NewOIDCHandler(map[string]*oidc.Provider{"mock": provider}, repos.User, repos.Identity, repos.Token),
+	}
+}
+
+// signIn runs the whole authorization code flow and returns the callback's response
+func (o *oidcTest) signIn(claims jwt.MapClaims) *httptest.ResponseRecorder {
+	o.t.Helper()
+	vars := map[string]string{"provider": "mock"}
+
+	rec := httptest.NewRecorder()
+	o.handler.LoginHandler(rec, mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/auth/oidc/mock/login", nil), vars))
+	if rec.Code != http.StatusFound {
+		o.t.Fatalf("login: status %d: %s", rec.Code, rec.Body)
+	}
+	code, state := o.idp.Authorize(rec.Header().Get("Location"), claims)
+
+	callback := "/auth/oidc/mock/callback?" + url.Values{"code": {code}, "state": {state}}.Encode()
+	rec = httptest.NewRecorder()
+	o.handler.CallbackHandler(rec, mux.SetURLVars(httptest.NewRequest(http.MethodGet, callback, nil), vars))
+	return rec
+}
+
+func (o *oidcTest) createUser(email string, verified bool) *models.User {
+	o.t.Helper()
+	user := &models.User{Username: "local_user", Email: email, Password: "$2a$10$notarealhashnotarealhashnotarealhashnotarealhashnot"}
+	if verified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := o.repos.User.CreateUser(user); err != nil {
+		o.t.Fatal(err)
+	}
+	return user
+}
+
+func TestOIDCSignInLinksOnlyVerifiedAccounts(t *testing.T) {
+	tests := []struct {
+		name          string
+		localVerified bool
+		idpVerified   bool
+		wantStatus    int
+		wantLinked    bool
+	}{
+		{name: "both verified", localVerified: true, idpVerified: true, wantStatus: http.StatusOK, wantLinked: true},
+		// Someone registered the address without confirming it; linking would hand them the account
+		{name: "local account unverified", localVerified: false, idpVerified: true, wantStatus: http.StatusConflict},
+		{name: "provider email unverified", localVerified: true, idpVerified: false, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOIDCTest(t)
+			user := o.createUser("victim@example.com", tt.localVerified)
+
+			rec := o.signIn(jwt.MapClaims{"email": "victim@example.com", "email_verified": tt.idpVerified})
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("callback: status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			identities, err := o.repos.Identity.GetByUserID(user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if linked := len(identities) == 1; linked != tt.wantLinked {
+				t.Fatalf("identity linked = %v, want %v", linked, tt.wantLinked)
+			}
+		})
+	}
+}
+
+func TestOIDCSignInAfterAccountDeletion(t *testing.T) {
+	o := newOIDCTest(t)
+	claims := jwt.MapClaims{"email": "leaver@example.com", "email_verified": true}
+
+	if rec := o.signIn(claims); rec.Code != http.StatusOK {
+		t.Fatalf("first sign-in: status %d: %s", rec.Code, rec.Body)
+	}
+	user, err := o.repos.User.GetByEmail("leaver@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.repos.User.DeleteUser(user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The provider subject must not point at the deleted account any more
+	if rec := o.signIn(claims); rec.Code != http.StatusOK {
+		t.Fatalf("sign-in after deletion: status %d: %s", rec.Code, rec.Body)
+	}
+	again, err := o.repos.User.GetByEmail("leaver@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID == user.ID {
+		t.Fatal("signed in to the deleted account")
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/mailer"
+	"devlink/internal/models"
+	"devlink/internal/password"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type PasswordHandler struct {
+	repo          *repository.UserRepository
+	oneTimeTokens *repository.OneTimeTokenRepository
+	tokens        *repository.TokenRepository
+	hasher        *password.Hasher
+	mailer        mailer.Mailer
+}
+
+func NewPasswordHandler(userRepository *repository.UserRepository, oneTimeTokenRepository *repository.OneTimeTokenRepository, tokenRepository *repository.TokenRepository, hasher *password.Hasher, m mailer.Mailer) *PasswordHandler {
+	return &PasswordHandler{
+		repo:          userRepository,
+		oneTimeTokens: oneTimeTokenRepository,
+		tokens:        tokenRepository,
+		hasher:        hasher,
+		mailer:        m,
+	}
+}
+
+// ForgotPasswordHandler emails a reset link. It answers the same way whether or not
+// the account exists so it cannot be used to discover registered emails.
+func (h *PasswordHandler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var forgotReq dto.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&forgotReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Validate email format
+	userTemp := models.User{Email: strings.TrimSpace(forgotReq.Email)}
+	if err := userTemp.ValidateEmail(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	const message = "If an account exists for that email, a reset link has been sent"
+
+	user, err := h.repo.GetByEmail(userTemp.Email)
+	if err != nil {
+		dto.WriteSuccess(w, http.StatusOK, nil, message)
+		return
+	}
+
+	ttl := config.GetEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+	token, err := issueOneTimeToken(h.oneTimeTokens, user.ID, models.TokenPurposePasswordReset, user.Email, ttl)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	link := appLink("/reset-password", token)
+	mailer.SendAsync(h.mailer, mailer.PasswordResetEmail(user.Email, link, ttl))
+
+	dto.WriteSuccess(w, http.StatusOK, nil, message)
+}
+
+func (h *PasswordHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var resetReq dto.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&resetReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if resetReq.Token == "" {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	// Check the new password before burning the token so a weak password can be retried
+	candidate := models.User{Password: resetReq.Password}
+	if err := candidate.ValidatePassword(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	token, err := h.oneTimeTokens.Consume(utils.HashToken(resetReq.Token), models.TokenPurposePasswordReset)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidOneTimeToken) {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err := h.repo.GetByID(token.UserID)
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidOneTimeToken)
+		return
+	}
+
+	// Hash password
+	hashedPassword, err := h.hasher.Hash(resetReq.Password)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	user.Password = hashedPassword
+	user.PasswordResetRequired = false
+
+	// Following the emailed link proves the user controls the address
+	if !user.IsEmailVerified() && token.Email == user.Email {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := h.repo.UpdateUser(user); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Sign out every existing session; whoever had the old password must not stay logged in
+	if err := h.tokens.RevokeAllForUser(user.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Password reset successfully. Please log in again")
+}
+
+// issueOneTimeToken creates and stores a token for a link emailed to email and returns its plain value
+func issueOneTimeToken(repo *repository.OneTimeTokenRepository, userID uint, purpose models.TokenPurpose, email string, ttl time.Duration) (string, error) {
+	plain, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	token := &models.OneTimeToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		Email:     email,
+		TokenHash: utils.HashToken(plain),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := repo.CreateToken(token); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// appLink builds a link into the web frontend carrying a token
+func appLink(path, token string) string {
+	return appURL(path) + "?token=" + url.QueryEscape(token)
+}
+
+// appURL builds a link to a page of the web frontend
+func appURL(path string) string {
+	return strings.TrimRight(config.GetEnv("APP_BASE_URL", "http://localhost:5173"), "/") + path
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/policy"
+	"devlink/internal/repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type ResourceGrantHandler struct {
+	repo      *repository.ResourceGrantRepository
+	resources *repository.ResourceRepository
+	users     *repository.UserRepository
+	policy    *policy.Policy
+}
+
+func NewResourceGrantHandler(grantRepository *repository.ResourceGrantRepository, resourceRepository *repository.ResourceRepository, userRepository *repository.UserRepository, resourcePolicy *policy.Policy) *ResourceGrantHandler {
+	return &ResourceGrantHandler{
+		repo:      grantRepository,
+		resources: resourceRepository,
+		users:     userRepository,
+		policy:    resourcePolicy,
+	}
+}
+
+// GrantResourceHandler shares a resource with a user, found by username or email. Granting to
+// someone who already has access changes their permission.
+func (h *ResourceGrantHandler) GrantResourceHandler(w http.ResponseWriter, r *http.Request) {
+	resource, userID, ok := authorizedResource(w, r, h.resources, h.policy, policy.ActionShare)
+	if !ok {
+		return
+	}
+
+	var grantReq dto.GrantResourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&grantReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !grantReq.Permission.IsValid() {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidGrantPermission)
+		return
+	}
+
+	var grantee *models.User
+	var err error
+	switch {
+	case strings.TrimSpace(grantReq.Username) != "":
+		grantee, err = h.users.GetByUsername(strings.TrimSpace(grantReq.Username))
+	case strings.TrimSpace(grantReq.Email) != "":
+		grantee, err = h.users.GetByEmail(strings.TrimSpace(grantReq.Email))
+	default:
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrUserNotFound)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if grantee.ID == resource.UserID {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrGrantToOwner)
+		return
+	}
+
+	grant := &models.ResourceGrant{
+		ResourceID:  resource.ID,
+		UserID:      grantee.ID,
+		Permission:  grantReq.Permission,
+		GrantedByID: userID,
+	}
+	if err := h.repo.Upsert(grant); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.ResourceGrantToResponse(grant, grantee.Username), "Resource shared successfully")
+}
+
+// GetResourceGrantsHandler lists who the resource is shared with
+func (h *ResourceGrantHandler) GetResourceGrantsHandler(w http.ResponseWriter, r *http.Request) {
+	resource, _, ok := authorizedResource(w, r, h.resources, h.policy, policy.ActionShare)
+	if !ok {
+		return
+	}
+
+	grants, err := h.repo.GetByResourceID(resource.ID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]dto.ResourceGrantResponse, len(grants))
+	for i, grant := range grants {
+		responses[i] = dto.ResourceGrantToResponse(&grant.ResourceGrant, grant.Username)
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, responses, "Resource grants retrieved successfully")
+}
+
+// UpdateResourceGrantHandler changes what a user the resource is shared with may do
+func (h *ResourceGrantHandler) UpdateResourceGrantHandler(w http.ResponseWriter, r *http.Request) {
+	resource, _, ok := authorizedResource(w, r, h.resources, h.policy, policy.ActionShare)
+	if !ok {
+		return
+	}
+	grant, ok := h.routeGrant(w, r, resource.ID)
+	if !ok {
+		return
+	}
+
+	var updateReq dto.UpdateGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !updateReq.Permission.IsValid() {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidGrantPermission)
+		return
+	}
+
+	grant.Permission = updateReq.Permission
+	if err := h.repo.Update(grant); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	grantee, err := h.users.GetByID(grant.UserID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.ResourceGrantToResponse(grant, grantee.Username), "Resource grant updated successfully")
+}
+
+// RevokeResourceGrantHandler stops sharing the resource with a user. Grantees can also remove
+// themselves.
+func (h *ResourceGrantHandler) RevokeResourceGrantHandler(w http.ResponseWriter, r *http.Request) {
+	resource, userID, ok := authorizedResource(w, r, h.resources, h.policy, policy.ActionRead)
+	if !ok {
+		return
+	}
+	grant, ok := h.routeGrant(w, r, resource.ID)
+	if !ok {
+		return
+	}
+	if grant.UserID != userID {
+		allowed, err := h.policy.CanAccessResource(userID, resource, policy.ActionShare)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !allowed {
+			dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+			return
+		}
+	}
+
+	if err := h.repo.Delete(grant); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Resource grant revoked successfully")
+}
+
+// GetSharedWithMeHandler lists the resources other users shared with the caller
+func (h *ResourceGrantHandler) GetSharedWithMeHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	page, pageSize := paginationParams(r)
+	shared, total, err := h.repo.GetSharedWith(userID, page, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]dto.SharedResourceResponse, len(shared))
+	for i, item := range shared {
+		responses[i] = dto.SharedResourceToResponse(&item.Resource, item.Permission, item.GrantedByID)
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(responses, "Shared resources retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+// routeGrant loads the grant of the user in the route on the resource
+func (h *ResourceGrantHandler) routeGrant(w http.ResponseWriter, r *http.Request, resourceID uint) (*models.ResourceGrant, bool) {
+	granteeID, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	grant, err := h.repo.Get(resourceID, uint(granteeID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrGrantNotFound)
+			return nil, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return grant, true
+}
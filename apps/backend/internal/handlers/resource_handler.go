@@ -1,25 +1,41 @@
 package handlers
 
 import (
+	"devlink/internal/config"
 	"devlink/internal/dto"
 	"devlink/internal/middleware"
 	"devlink/internal/models"
+	"devlink/internal/policy"
 	"devlink/internal/repository"
+	"devlink/internal/urlnorm"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type ResourceHandler struct {
-	repo *repository.ResourceRepository
+	repo                 *repository.ResourceRepository
+	users                *repository.UserRepository
+	folders              *repository.FolderRepository
+	policy               *policy.Policy
+	urls                 *urlnorm.Normalizer
+	requireVerifiedEmail bool
 }
 
-func NewResourceHandler(resourceRepository *repository.ResourceRepository) *ResourceHandler {
+func NewResourceHandler(resourceRepository *repository.ResourceRepository, userRepository *repository.UserRepository, folderRepository *repository.FolderRepository, resourcePolicy *policy.Policy, urls *urlnorm.Normalizer) *ResourceHandler {
 	return &ResourceHandler{
-		repo: resourceRepository,
+		repo:                 resourceRepository,
+		users:                userRepository,
+		folders:              folderRepository,
+		policy:               resourcePolicy,
+		urls:                 urls,
+		requireVerifiedEmail: config.GetEnvBool("REQUIRE_VERIFIED_EMAIL", false),
 	}
 }
 
@@ -38,6 +54,39 @@ func (h *ResourceHandler) CreateResourceHandler(w http.ResponseWriter, r *http.R
 	}
 	userID := uint(claims["user_id"].(float64))
 
+	// Unverified accounts may be blocked from creating content
+	if h.requireVerifiedEmail {
+		user, err := h.users.GetByID(userID)
+		if err != nil {
+			dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+			return
+		}
+		if !user.IsEmailVerified() {
+			dto.WriteError(w, http.StatusForbidden, models.ErrEmailNotVerified)
+			return
+		}
+	}
+
+	// Workspace resources need an editor; the folder, if any, must be one of the user's own
+	if createReq.WorkspaceID != nil {
+		if createReq.FolderID != nil {
+			dto.WriteError(w, http.StatusBadRequest, models.ErrWorkspaceFolder)
+			return
+		}
+		if _, ok := workspaceAccess(w, h.policy, userID, *createReq.WorkspaceID, models.WorkspaceRoleEditor); !ok {
+			return
+		}
+	}
+	if _, ok := userFolder(w, h.folders, userID, createReq.FolderID); !ok {
+		return
+	}
+
+	// Store the normalized URL and keep the one sent
+	normalizedURL, ok := h.normalizeURL(w, createReq.URL)
+	if !ok {
+		return
+	}
+
 	// Marshal tags to JSON
 	tagsJSON, err := json.Marshal(createReq.Tags)
 	if err != nil {
@@ -49,13 +98,17 @@ func (h *ResourceHandler) CreateResourceHandler(w http.ResponseWriter, r *http.R
 	resource := &models.Resource{
 		Title:       createReq.Title,
 		Type:        createReq.Type,
-		URL:         createReq.URL,
+		URL:         normalizedURL,
+		RawURL:      createReq.URL,
 		Category:    createReq.Category,
 		Description: createReq.Description,
 		Tags:        datatypes.JSON(tagsJSON),
 		Language:    createReq.Language,
 		CodeContent: createReq.CodeContent,
 		UserID:      userID,
+		WorkspaceID: createReq.WorkspaceID,
+		FolderID:    createReq.FolderID,
+		Visibility:  createReq.Visibility,
 	}
 
 	// Validate resource based on type
@@ -65,7 +118,7 @@ func (h *ResourceHandler) CreateResourceHandler(w http.ResponseWriter, r *http.R
 	}
 
 	if err := h.repo.CreateResource(resource); err != nil {
-		dto.WriteError(w, http.StatusInternalServerError, err)
+		writeResourceSaveError(w, err)
 		return
 	}
 
@@ -73,28 +126,23 @@ func (h *ResourceHandler) CreateResourceHandler(w http.ResponseWriter, r *http.R
 }
 
 func (h *ResourceHandler) GetResourceByIDHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	resourceID, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		dto.WriteError(w, http.StatusBadRequest, err)
-		return
-	}
-
-	resource, err := h.repo.GetByID(uint(resourceID))
-	if err != nil {
-		dto.WriteError(w, http.StatusNotFound, err)
+	resource, userID, ok := authorizedResource(w, r, h.repo, h.policy, policy.ActionRead)
+	if !ok {
 		return
 	}
 
-	// Check if user owns the resource
-	claims, ok := middleware.GetUserClaims(r)
-	if !ok {
-		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
-		return
+	// Readers outside the resource's library see it without the owner's folder layout
+	inLibrary := resource.WorkspaceID == nil && resource.UserID == userID
+	if resource.WorkspaceID != nil {
+		role, err := h.policy.WorkspaceRole(*resource.WorkspaceID, userID)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		inLibrary = role != ""
 	}
-	userID := uint(claims["user_id"].(float64))
-	if resource.UserID != userID {
-		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+	if !inLibrary {
+		dto.WriteSuccess(w, http.StatusOK, dto.ResourceToPublicResponse(resource), "Resource retrieved successfully")
 		return
 	}
 
@@ -122,8 +170,44 @@ func (h *ResourceHandler) GetUserResourcesHandler(w http.ResponseWriter, r *http
 		pageSize = 10
 	}
 
-	// Get resources
-	resources, total, err := h.repo.GetByUserID(userID, page, pageSize)
+	// List a workspace's library with ?workspace=<id>
+	var resources []models.Resource
+	var total int64
+	var err error
+	if workspaceParam := r.URL.Query().Get("workspace"); workspaceParam != "" {
+		workspaceID, convErr := strconv.Atoi(workspaceParam)
+		if convErr != nil {
+			dto.WriteError(w, http.StatusBadRequest, convErr)
+			return
+		}
+		id := uint(workspaceID)
+		if _, ok := workspaceAccess(w, h.policy, userID, id, models.WorkspaceRoleViewer); !ok {
+			return
+		}
+		resources, total, err = h.repo.List(repository.ResourceFilter{WorkspaceID: &id}, page, pageSize)
+	} else if folderParam := r.URL.Query().Get("folder"); folderParam != "" {
+		// Optionally narrow to a folder (?folder=<id> or ?folder=root), with ?recursive=true for its subfolders
+		var folder *models.Folder
+		if folderParam != "root" {
+			folderID, convErr := strconv.Atoi(folderParam)
+			if convErr != nil {
+				dto.WriteError(w, http.StatusBadRequest, convErr)
+				return
+			}
+			id := uint(folderID)
+			if folder, ok = userFolder(w, h.folders, userID, &id); !ok {
+				return
+			}
+		}
+		recursive, _ := strconv.ParseBool(r.URL.Query().Get("recursive"))
+		resources, total, err = h.repo.List(repository.ResourceFilter{
+			UserID: userID,
+			Folder: &repository.FolderScope{Folder: folder, Recursive: recursive},
+		}, page, pageSize)
+	} else {
+		// Get resources
+		resources, total, err = h.repo.GetByUserID(userID, page, pageSize)
+	}
 	if err != nil {
 		dto.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -140,29 +224,8 @@ func (h *ResourceHandler) GetUserResourcesHandler(w http.ResponseWriter, r *http
 }
 
 func (h *ResourceHandler) UpdateResourceHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	resourceID, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		dto.WriteError(w, http.StatusBadRequest, err)
-		return
-	}
-
-	// Get existing resource
-	resource, err := h.repo.GetByID(uint(resourceID))
-	if err != nil {
-		dto.WriteError(w, http.StatusNotFound, err)
-		return
-	}
-
-	// Check if user owns the resource
-	claims, ok := middleware.GetUserClaims(r)
+	resource, userID, ok := authorizedResource(w, r, h.repo, h.policy, policy.ActionEdit)
 	if !ok {
-		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
-		return
-	}
-	userID := uint(claims["user_id"].(float64))
-	if resource.UserID != userID {
-		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
 		return
 	}
 
@@ -181,7 +244,12 @@ func (h *ResourceHandler) UpdateResourceHandler(w http.ResponseWriter, r *http.R
 		resource.Type = updateReq.Type
 	}
 	if updateReq.URL != "" {
-		resource.URL = updateReq.URL
+		normalizedURL, ok := h.normalizeURL(w, updateReq.URL)
+		if !ok {
+			return
+		}
+		resource.URL = normalizedURL
+		resource.RawURL = updateReq.URL
 	}
 	if updateReq.Category != "" {
 		resource.Category = updateReq.Category
@@ -203,6 +271,19 @@ func (h *ResourceHandler) UpdateResourceHandler(w http.ResponseWriter, r *http.R
 	if updateReq.CodeContent != "" {
 		resource.CodeContent = updateReq.CodeContent
 	}
+	if updateReq.Visibility != "" && updateReq.Visibility != resource.Visibility {
+		// Publishing is a sharing decision, which not every editor may make
+		allowed, err := h.policy.CanAccessResource(userID, resource, policy.ActionShare)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !allowed {
+			dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+			return
+		}
+		resource.Visibility = updateReq.Visibility
+	}
 
 	// Validate resource based on type
 	if err := resource.Validate(); err != nil {
@@ -211,41 +292,97 @@ func (h *ResourceHandler) UpdateResourceHandler(w http.ResponseWriter, r *http.R
 	}
 
 	if err := h.repo.UpdateResource(resource); err != nil {
-		dto.WriteError(w, http.StatusInternalServerError, err)
+		writeResourceSaveError(w, err)
 		return
 	}
 
 	dto.WriteSuccess(w, http.StatusOK, dto.ResourceToResponse(resource), "Resource updated successfully")
 }
 
-func (h *ResourceHandler) DeleteResourceHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	resourceID, err := strconv.Atoi(vars["id"])
-	if err != nil {
-		dto.WriteError(w, http.StatusBadRequest, err)
+// LookupLinkHandler checks a URL before saving it: whether the caller's library, or the workspace
+// in ?workspace=, already has it and how many resources across the instance save it
+func (h *ResourceHandler) LookupLinkHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
 		return
 	}
+	userID := uint(claims["user_id"].(float64))
 
-	// Get existing resource
-	resource, err := h.repo.GetByID(uint(resourceID))
+	rawURL := strings.TrimSpace(r.URL.Query().Get("url"))
+	if rawURL == "" {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	var workspaceID *uint
+	if workspaceParam := r.URL.Query().Get("workspace"); workspaceParam != "" {
+		parsed, err := strconv.Atoi(workspaceParam)
+		if err != nil {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		id := uint(parsed)
+		if _, ok := workspaceAccess(w, h.policy, userID, id, models.WorkspaceRoleViewer); !ok {
+			return
+		}
+		workspaceID = &id
+	}
+
+	lookup, err := h.repo.LookupLink(rawURL, userID, workspaceID)
 	if err != nil {
-		dto.WriteError(w, http.StatusNotFound, err)
+		if errors.Is(err, urlnorm.ErrInvalidURL) {
+			dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidURL)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	// Check if user owns the resource
-	claims, ok := middleware.GetUserClaims(r)
+	dto.WriteSuccess(w, http.StatusOK, dto.LinkLookupResponse{
+		URL:        lookup.URL,
+		SaveCount:  lookup.SaveCount,
+		ResourceID: lookup.ResourceID,
+	}, "Link looked up successfully")
+}
+
+// MoveResourceHandler files a resource in another folder or at the top level
+func (h *ResourceHandler) MoveResourceHandler(w http.ResponseWriter, r *http.Request) {
+	resource, userID, ok := authorizedResource(w, r, h.repo, h.policy, policy.ActionOrganize)
 	if !ok {
-		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
 		return
 	}
-	userID := uint(claims["user_id"].(float64))
-	if resource.UserID != userID {
-		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+	if resource.WorkspaceID != nil {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrWorkspaceFolder)
+		return
+	}
+
+	var moveReq dto.MoveResourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&moveReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if _, ok := userFolder(w, h.folders, userID, moveReq.FolderID); !ok {
+		return
+	}
+
+	if err := h.repo.MoveToFolder(resource.ID, moveReq.FolderID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	resource.FolderID = moveReq.FolderID
+
+	dto.WriteSuccess(w, http.StatusOK, dto.ResourceToResponse(resource), "Resource moved successfully")
+}
+
+func (h *ResourceHandler) DeleteResourceHandler(w http.ResponseWriter, r *http.Request) {
+	resource, _, ok := authorizedResource(w, r, h.repo, h.policy, policy.ActionDelete)
+	if !ok {
 		return
 	}
 
-	if err := h.repo.DeleteResource(uint(resourceID)); err != nil {
+	if err := h.repo.DeleteResource(resource.ID); err != nil {
 		dto.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -289,7 +426,7 @@ func (h *ResourceHandler) SearchResourcesHandler(w http.ResponseWriter, r *http.
 	}
 
 	response := dto.PaginatedResponse{
-		Response: dto.NewSuccessResponse(dto.ResourcesToResponse(resources), "Resources retrieved successfully"),
+		Response: dto.NewSuccessResponse(dto.ResourcesToResponseFor(resources, userID), "Resources retrieved successfully"),
 		Page:     page,
 		PageSize: pageSize,
 		Total:    int(total),
@@ -341,7 +478,44 @@ func (h *ResourceHandler) GetResourcesByTagsHandler(w http.ResponseWriter, r *ht
 	}
 
 	response := dto.PaginatedResponse{
-		Response: dto.NewSuccessResponse(dto.ResourcesToResponse(resources), "Resources retrieved successfully"),
+		Response: dto.NewSuccessResponse(dto.ResourcesToResponseFor(resources, userID), "Resources retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetPublicResourcesHandler lists public resources for anonymous readers.
+// ?owner=<username> narrows the feed to one user and ?q= searches it.
+func (h *ResourceHandler) GetPublicResourcesHandler(w http.ResponseWriter, r *http.Request) {
+	filter := repository.ResourceFilter{
+		Visibility: models.VisibilityPublic,
+		Query:      strings.TrimSpace(r.URL.Query().Get("q")),
+	}
+	if owner := r.URL.Query().Get("owner"); owner != "" {
+		user, err := h.users.GetByUsername(owner)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				dto.WriteError(w, http.StatusNotFound, models.ErrUserNotFound)
+				return
+			}
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		filter.UserID = user.ID
+	}
+
+	page, pageSize := paginationParams(r)
+	resources, total, err := h.repo.List(filter, page, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(dto.ResourcesToPublicResponse(resources), "Resources retrieved successfully"),
 		Page:     page,
 		PageSize: pageSize,
 		Total:    int(total),
@@ -349,3 +523,91 @@ func (h *ResourceHandler) GetResourcesByTagsHandler(w http.ResponseWriter, r *ht
 
 	dto.WriteJSON(w, http.StatusOK, response)
 }
+
+// GetPublicResourceHandler returns a public or unlisted resource to anyone. Private resources
+// get the same 404 as missing ones so their IDs cannot be probed.
+func (h *ResourceHandler) GetPublicResourceHandler(w http.ResponseWriter, r *http.Request) {
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resource, err := h.repo.GetByID(uint(resourceID))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || !resource.IsPubliclyReadable() {
+		dto.WriteError(w, http.StatusNotFound, models.ErrResourceNotFound)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.ResourceToPublicResponse(resource), "Resource retrieved successfully")
+}
+
+// authorizedResource loads the resource in the route and checks the caller may perform action on it
+func authorizedResource(w http.ResponseWriter, r *http.Request, resources *repository.ResourceRepository, access *policy.Policy, action policy.Action) (*models.Resource, uint, bool) {
+	resourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, 0, false
+	}
+
+	resource, err := resources.GetByID(uint(resourceID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrResourceNotFound)
+			return nil, 0, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, 0, false
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, 0, false
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	allowed, err := access.CanAccessResource(userID, resource, action)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, 0, false
+	}
+	if !allowed {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return nil, 0, false
+	}
+	return resource, userID, true
+}
+
+// normalizeURL normalizes a URL from a request; an empty URL stays empty
+func (h *ResourceHandler) normalizeURL(w http.ResponseWriter, rawURL string) (string, bool) {
+	if rawURL == "" {
+		return "", true
+	}
+	normalized, err := h.urls.Normalize(rawURL)
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidURL)
+		return "", false
+	}
+	return normalized, true
+}
+
+// writeResourceSaveError answers a failed create or update; a link the library already has is a
+// 409 that points at the resource holding it
+func writeResourceSaveError(w http.ResponseWriter, err error) {
+	var duplicate *models.DuplicateResourceError
+	if errors.As(err, &duplicate) {
+		dto.WriteJSON(w, http.StatusConflict, dto.Response{
+			Success: false,
+			Error:   duplicate.Error(),
+			Data:    dto.DuplicateResourceResponse{ExistingResourceID: duplicate.ExistingResourceID},
+		})
+		return
+	}
+	dto.WriteError(w, http.StatusInternalServerError, err)
+}
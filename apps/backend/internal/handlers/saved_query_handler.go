@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type SavedQueryHandler struct {
+	repo      *repository.SavedQueryRepository
+	resources *repository.ResourceRepository
+}
+
+func NewSavedQueryHandler(savedQueryRepository *repository.SavedQueryRepository, resourceRepository *repository.ResourceRepository) *SavedQueryHandler {
+	return &SavedQueryHandler{
+		repo:      savedQueryRepository,
+		resources: resourceRepository,
+	}
+}
+
+func (h *SavedQueryHandler) CreateSavedQueryHandler(w http.ResponseWriter, r *http.Request) {
+	var createReq dto.CreateSavedQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	// Marshal tags to JSON
+	tagsJSON, err := json.Marshal(createReq.Tags)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	savedQuery := &models.SavedQuery{
+		UserID:        userID,
+		Name:          createReq.Name,
+		Query:         createReq.Query,
+		Tags:          datatypes.JSON(tagsJSON),
+		Type:          createReq.Type,
+		Category:      createReq.Category,
+		Language:      createReq.Language,
+		CreatedAfter:  createReq.CreatedAfter,
+		CreatedBefore: createReq.CreatedBefore,
+	}
+	if createReq.Pinned {
+		now := time.Now()
+		savedQuery.PinnedAt = &now
+	}
+	if err := savedQuery.Validate(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.repo.CreateSavedQuery(savedQuery); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeSavedQuery(w, http.StatusCreated, savedQuery, "Saved query created successfully")
+}
+
+// GetSavedQueriesHandler lists the user's saved queries, pinned first, each with its current match count
+func (h *SavedQueryHandler) GetSavedQueriesHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	page, pageSize := paginationParams(r)
+	savedQueries, total, err := h.repo.GetByUserID(userID, page, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Count the matches of the whole page in one pass over the library
+	filters := make([]repository.ResourceFilter, len(savedQueries))
+	for i := range savedQueries {
+		filters[i] = savedQueryFilter(&savedQueries[i])
+	}
+	counts, err := h.resources.CountEach(savedQueryLibrary(userID), filters)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]dto.SavedQueryResponse, len(savedQueries))
+	for i := range savedQueries {
+		responses[i] = dto.SavedQueryToResponse(&savedQueries[i], counts[i])
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(responses, "Saved queries retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *SavedQueryHandler) GetSavedQueryHandler(w http.ResponseWriter, r *http.Request) {
+	savedQuery, ok := h.ownedSavedQuery(w, r)
+	if !ok {
+		return
+	}
+	h.writeSavedQuery(w, http.StatusOK, savedQuery, "Saved query retrieved successfully")
+}
+
+// GetSavedQueryResourcesHandler runs the saved query and returns a page of the resources it matches now
+func (h *SavedQueryHandler) GetSavedQueryResourcesHandler(w http.ResponseWriter, r *http.Request) {
+	savedQuery, ok := h.ownedSavedQuery(w, r)
+	if !ok {
+		return
+	}
+
+	page, pageSize := paginationParams(r)
+	resources, total, err := h.resources.List(savedQueryFilter(savedQuery), page, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(dto.ResourcesToResponse(resources), "Resources retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *SavedQueryHandler) UpdateSavedQueryHandler(w http.ResponseWriter, r *http.Request) {
+	savedQuery, ok := h.ownedSavedQuery(w, r)
+	if !ok {
+		return
+	}
+
+	var updateReq dto.UpdateSavedQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Update fields if provided
+	if updateReq.Name != nil {
+		savedQuery.Name = *updateReq.Name
+	}
+	if updateReq.Query != nil {
+		savedQuery.Query = *updateReq.Query
+	}
+	if updateReq.Tags != nil {
+		tagsJSON, err := json.Marshal(*updateReq.Tags)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		savedQuery.Tags = datatypes.JSON(tagsJSON)
+	}
+	if updateReq.Type != nil {
+		savedQuery.Type = *updateReq.Type
+	}
+	if updateReq.Category != nil {
+		savedQuery.Category = *updateReq.Category
+	}
+	if updateReq.Language != nil {
+		savedQuery.Language = *updateReq.Language
+	}
+	if updateReq.ClearDates {
+		savedQuery.CreatedAfter = nil
+		savedQuery.CreatedBefore = nil
+	}
+	if updateReq.CreatedAfter != nil {
+		savedQuery.CreatedAfter = updateReq.CreatedAfter
+	}
+	if updateReq.CreatedBefore != nil {
+		savedQuery.CreatedBefore = updateReq.CreatedBefore
+	}
+	if updateReq.Pinned != nil {
+		switch {
+		case *updateReq.Pinned && savedQuery.PinnedAt == nil:
+			now := time.Now()
+			savedQuery.PinnedAt = &now
+		case !*updateReq.Pinned:
+			savedQuery.PinnedAt = nil
+		}
+	}
+	if err := savedQuery.Validate(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.repo.UpdateSavedQuery(savedQuery); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.writeSavedQuery(w, http.StatusOK, savedQuery, "Saved query updated successfully")
+}
+
+func (h *SavedQueryHandler) DeleteSavedQueryHandler(w http.ResponseWriter, r *http.Request) {
+	savedQuery, ok := h.ownedSavedQuery(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteSavedQuery(savedQuery.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Saved query deleted successfully")
+}
+
+// writeSavedQuery responds with the saved query and the number of resources it matches right now
+func (h *SavedQueryHandler) writeSavedQuery(w http.ResponseWriter, status int, savedQuery *models.SavedQuery, message string) {
+	count, err := h.resources.Count(savedQueryFilter(savedQuery))
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	dto.WriteSuccess(w, status, dto.SavedQueryToResponse(savedQuery, count), message)
+}
+
+// ownedSavedQuery loads the saved query in the route if it belongs to the caller
+func (h *SavedQueryHandler) ownedSavedQuery(w http.ResponseWriter, r *http.Request) (*models.SavedQuery, bool) {
+	savedQueryID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	savedQuery, err := h.repo.GetByID(uint(savedQueryID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrSavedQueryNotFound)
+			return nil, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	// Check if user owns the saved query
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, false
+	}
+	userID := uint(claims["user_id"].(float64))
+	if savedQuery.UserID != userID {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return nil, false
+	}
+	return savedQuery, true
+}
+
+// savedQueryLibrary is what saved queries search: like search itself, the user's personal
+// library plus what others shared with them
+func savedQueryLibrary(userID uint) repository.ResourceFilter {
+	return repository.ResourceFilter{UserID: userID, IncludeShared: true}
+}
+
+// savedQueryFilter turns the saved criteria into a resource filter for the query's owner
+func savedQueryFilter(savedQuery *models.SavedQuery) repository.ResourceFilter {
+	filter := savedQueryLibrary(savedQuery.UserID)
+	filter.Query = savedQuery.Query
+	filter.Tags = savedQuery.TagList()
+	filter.Type = savedQuery.Type
+	filter.Category = savedQuery.Category
+	filter.Language = savedQuery.Language
+	filter.CreatedAfter = savedQuery.CreatedAfter
+	filter.CreatedBefore = savedQuery.CreatedBefore
+	return filter
+}
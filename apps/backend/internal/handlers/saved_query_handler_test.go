@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"devlink/internal/db"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/urlnorm"
+)
+
+func TestSavedQueryCountsSharedResourcesLikeSearch(t *testing.T) {
+	repos := repository.NewRepositories(db.InitDB(filepath.Join(t.TempDir(), "test.db")), urlnorm.NewFromEnv())
+
+	var users []*models.User
+	for _, name := range []string{"owner", "reader"} {
+		user := &models.User{Username: name, Email: name + "@example.com"}
+		if err := repos.User.CreateUser(user); err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, user)
+	}
+	owner, reader := users[0], users[1]
+
+	for _, resource := range []*models.Resource{
+		{Title: "Shared gopher guide", Type: models.ResourceTypeLink, URL: "https://example.com/shared", Category: models.LinkCategoryArticle, UserID: owner.ID},
+		{Title: "Own gopher notes", Type: models.ResourceTypeLink, URL: "https://example.com/own", Category: models.LinkCategoryArticle, UserID: reader.ID},
+		{Title: "Private gopher diary", Type: models.ResourceTypeLink, URL: "https://example.com/private", Category: models.LinkCategoryArticle, UserID: owner.ID},
+	} {
+		if err := repos.Resource.CreateResource(resource); err != nil {
+			t.Fatal(err)
+		}
+		if resource.Title == "Shared gopher guide" {
+			grant := &models.ResourceGrant{ResourceID: resource.ID, UserID: reader.ID, Permission: models.GrantPermissionRead, GrantedByID: owner.ID}
+			if err := repos.ResourceGrant.Upsert(grant); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	_, searched, err := repos.Resource.SearchResources("gopher", reader.ID, 1, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	savedQuery := &models.SavedQuery{UserID: reader.ID, Query: "gopher"}
+	_, listed, err := repos.Resource.List(savedQueryFilter(savedQuery), 1, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err := repos.Resource.CountEach(savedQueryLibrary(reader.ID), []repository.ResourceFilter{savedQueryFilter(savedQuery)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if searched != 2 || listed != searched || counts[0] != searched {
+		t.Fatalf("search found %d, saved query lists %d and counts %d; want 2 each", searched, listed, counts[0])
+	}
+}
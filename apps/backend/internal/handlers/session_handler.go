@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type SessionHandler struct {
+	tokens *repository.TokenRepository
+}
+
+func NewSessionHandler(tokenRepository *repository.TokenRepository) *SessionHandler {
+	return &SessionHandler{
+		tokens: tokenRepository,
+	}
+}
+
+// GetSessionsHandler lists the devices the user is signed in on
+func (h *SessionHandler) GetSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	sessions, err := h.tokens.GetActiveSessions(userID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.SessionsToResponse(sessions, currentSessionID(claims)), "Sessions retrieved successfully")
+}
+
+// RevokeSessionHandler signs one device out. Its tokens stop working immediately.
+func (h *SessionHandler) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	session, err := h.tokens.GetSessionByID(userID, uint(sessionID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrSessionNotFound)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := h.tokens.RevokeFamily(session.FamilyID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if session.ID == currentSessionID(claims) && middleware.HasSessionCookies(r) {
+		middleware.ClearSessionCookies(w)
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Session signed out successfully")
+}
+
+// RevokeAllSessionsHandler signs the user out everywhere. With ?keep_current=true the
+// session making the request stays signed in.
+func (h *SessionHandler) RevokeAllSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	keepCurrent, _ := strconv.ParseBool(r.URL.Query().Get("keep_current"))
+	keepFamilyID := ""
+	if familyID, _ := claims["fid"].(string); keepCurrent && familyID != "" {
+		keepFamilyID = familyID
+	}
+
+	if err := h.tokens.RevokeAllForUserExcept(userID, keepFamilyID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if keepFamilyID == "" && middleware.HasSessionCookies(r) {
+		middleware.ClearSessionCookies(w)
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Signed out everywhere")
+}
+
+// currentSessionID returns the session the request was authenticated with, or 0 for personal access tokens
+func currentSessionID(claims map[string]interface{}) uint {
+	sid, _ := claims["sid"].(float64)
+	return uint(sid)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"devlink/internal/dto"
+	"devlink/internal/models"
+	"devlink/internal/policy"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type ShareLinkHandler struct {
+	repo      *repository.ShareLinkRepository
+	resources *repository.ResourceRepository
+	policy    *policy.Policy
+}
+
+func NewShareLinkHandler(shareLinkRepository *repository.ShareLinkRepository, resourceRepository *repository.ResourceRepository, resourcePolicy *policy.Policy) *ShareLinkHandler {
+	return &ShareLinkHandler{
+		repo:      shareLinkRepository,
+		resources: resourceRepository,
+		policy:    resourcePolicy,
+	}
+}
+
+// CreateShareLinkHandler creates a share link for a resource the caller may share
+func (h *ShareLinkHandler) CreateShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	resource, userID, ok := authorizedResource(w, r, h.resources, h.policy, policy.ActionShare)
+	if !ok {
+		return
+	}
+
+	// The body is optional; without one the link never expires
+	var createReq dto.CreateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil && !errors.Is(err, io.EOF) {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if createReq.ExpiresInHours < 0 || createReq.ExpiresInHours > 8760 {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidShareLinkExpiry)
+		return
+	}
+
+	// Generate the token; only its hash is persisted
+	secret, err := utils.GenerateRandomToken(24)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	token := models.ShareLinkTokenPrefix + secret
+
+	link := &models.ShareLink{
+		ResourceID:  resource.ID,
+		CreatedByID: userID,
+		TokenHash:   utils.HashToken(token),
+		TokenPrefix: token[:len(models.ShareLinkTokenPrefix)+6],
+	}
+	if createReq.ExpiresInHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(createReq.ExpiresInHours) * time.Hour)
+		link.ExpiresAt = &expiresAt
+	}
+	if err := h.repo.Create(link); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.CreatedShareLinkResponse{
+		ShareLinkResponse: dto.ShareLinkToResponse(link),
+		Token:             token,
+		URL:               appURL("/share/" + token),
+	}, "Share link created successfully. Copy the link now, it will not be shown again")
+}
+
+// GetShareLinksHandler lists every share link of a resource, including revoked and expired ones
+func (h *ShareLinkHandler) GetShareLinksHandler(w http.ResponseWriter, r *http.Request) {
+	resource, _, ok := authorizedResource(w, r, h.resources, h.policy, policy.ActionShare)
+	if !ok {
+		return
+	}
+
+	links, err := h.repo.GetByResourceID(resource.ID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.ShareLinksToResponse(links), "Share links retrieved successfully")
+}
+
+// RevokeShareLinkHandler stops a share link from working
+func (h *ShareLinkHandler) RevokeShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	resource, _, ok := authorizedResource(w, r, h.resources, h.policy, policy.ActionShare)
+	if !ok {
+		return
+	}
+
+	linkID, err := strconv.Atoi(mux.Vars(r)["linkId"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	link, err := h.repo.GetByID(uint(linkID))
+	if err != nil || link.ResourceID != resource.ID {
+		dto.WriteError(w, http.StatusNotFound, models.ErrShareLinkNotFound)
+		return
+	}
+
+	if err := h.repo.Revoke(link.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Share link revoked successfully")
+}
+
+// GetSharedResourceHandler returns the resource behind a share token to anyone holding it.
+// Unknown, revoked and expired tokens all get the same 404.
+func (h *ShareLinkHandler) GetSharedResourceHandler(w http.ResponseWriter, r *http.Request) {
+	link, err := h.repo.GetByTokenHash(utils.HashToken(mux.Vars(r)["token"]))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err != nil || !link.IsActive(time.Now()) {
+		dto.WriteError(w, http.StatusNotFound, models.ErrInvalidShareLink)
+		return
+	}
+
+	resource, err := h.resources.GetByID(link.ResourceID)
+	if err != nil {
+		dto.WriteError(w, http.StatusNotFound, models.ErrInvalidShareLink)
+		return
+	}
+
+	if err := h.repo.RecordView(link.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Shared pages should not be cached by intermediaries, so revoking takes effect at once
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	dto.WriteSuccess(w, http.StatusOK, dto.ResourceToPublicResponse(resource), "Resource retrieved successfully")
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"devlink/internal/dto"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const defaultTokenExpiryDays = 30
+
+type TokenHandler struct {
+	repo *repository.AccessTokenRepository
+}
+
+func NewTokenHandler(accessTokenRepository *repository.AccessTokenRepository) *TokenHandler {
+	return &TokenHandler{
+		repo: accessTokenRepository,
+	}
+}
+
+func (h *TokenHandler) CreateTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var createReq dto.CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	// Validate request
+	createReq.Name = strings.TrimSpace(createReq.Name)
+	if createReq.Name == "" || len(createReq.Name) > 100 {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidTokenName)
+		return
+	}
+	if err := models.ValidateScopes(createReq.Scopes); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if createReq.ExpiresInDays == 0 {
+		createReq.ExpiresInDays = defaultTokenExpiryDays
+	}
+	if createReq.ExpiresInDays < 1 || createReq.ExpiresInDays > 365 {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidExpiry)
+		return
+	}
+
+	// A token cannot grant more than the token used to create it
+	for _, scope := range createReq.Scopes {
+		if !middleware.HasScope(claims, scope) {
+			dto.WriteError(w, http.StatusForbidden, models.ErrInsufficientScope)
+			return
+		}
+	}
+
+	// Generate the token; only its hash is persisted
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	plain := models.PersonalAccessTokenPrefix + secret
+
+	token := &models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        createReq.Name,
+		TokenHash:   utils.HashToken(plain),
+		TokenPrefix: plain[:len(models.PersonalAccessTokenPrefix)+6],
+		Scopes:      strings.Join(createReq.Scopes, " "),
+		ExpiresAt:   time.Now().AddDate(0, 0, createReq.ExpiresInDays),
+	}
+
+	if err := h.repo.CreateToken(token); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.CreatedTokenResponse{
+		TokenResponse: dto.TokenToResponse(token),
+		Token:         plain,
+	}, "Token created successfully. Copy it now, it will not be shown again")
+}
+
+func (h *TokenHandler) GetUserTokensHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	tokens, err := h.repo.GetByUserID(userID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.TokensToResponse(tokens), "Tokens retrieved successfully")
+}
+
+func (h *TokenHandler) RevokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tokenID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	token, err := h.repo.GetByID(uint(tokenID))
+	if err != nil {
+		dto.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	// Check if user owns the token
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+	if token.UserID != userID {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return
+	}
+
+	if err := h.repo.RevokeToken(token.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Token revoked successfully")
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/mailer"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type TransferHandler struct {
+	repo   *repository.TransferRepository
+	users  *repository.UserRepository
+	mailer mailer.Mailer
+}
+
+func NewTransferHandler(transferRepository *repository.TransferRepository, userRepository *repository.UserRepository, m mailer.Mailer) *TransferHandler {
+	return &TransferHandler{
+		repo:   transferRepository,
+		users:  userRepository,
+		mailer: m,
+	}
+}
+
+// CreateTransferHandler offers personal resources, or the whole personal library, to another user
+func (h *TransferHandler) CreateTransferHandler(w http.ResponseWriter, r *http.Request) {
+	var createReq dto.CreateTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	resourceIDs := uniqueIDs(createReq.ResourceIDs)
+	if createReq.All == (len(resourceIDs) > 0) || len(resourceIDs) > models.MaxTransferResources {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidTransferSelection)
+		return
+	}
+	if len(createReq.Message) > 500 {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	var recipient *models.User
+	var err error
+	switch {
+	case strings.TrimSpace(createReq.Username) != "":
+		recipient, err = h.users.GetByUsername(strings.TrimSpace(createReq.Username))
+	case strings.TrimSpace(createReq.Email) != "":
+		recipient, err = h.users.GetByEmail(strings.TrimSpace(createReq.Email))
+	default:
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrUserNotFound)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if recipient.ID == userID {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrTransferToSelf)
+		return
+	}
+
+	// Every picked resource has to be in the caller's personal library
+	if !createReq.All {
+		owned, err := h.repo.CountPersonal(userID, resourceIDs)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if owned != int64(len(resourceIDs)) {
+			dto.WriteError(w, http.StatusForbidden, models.ErrTransferResourceNotOwned)
+			return
+		}
+	}
+
+	transfer := &models.ResourceTransfer{
+		FromUserID:   userID,
+		ToUserID:     recipient.ID,
+		WholeAccount: createReq.All,
+		Message:      strings.TrimSpace(createReq.Message),
+		Status:       models.TransferStatusPending,
+		ExpiresAt:    time.Now().Add(config.GetEnvDuration("TRANSFER_TTL", 7*24*time.Hour)),
+	}
+	if !createReq.All {
+		idsJSON, err := json.Marshal(resourceIDs)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		transfer.ResourceIDs = idsJSON
+	}
+
+	if err := h.repo.Create(transfer); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if sender, ok := middleware.GetCurrentUser(r); ok {
+		what := "all of their personal resources"
+		if !transfer.WholeAccount {
+			what = fmt.Sprintf("%d resource(s)", len(resourceIDs))
+		}
+		link := appURL(fmt.Sprintf("/transfers/%d", transfer.ID))
+		mailer.SendAsync(h.mailer, mailer.ResourceTransferEmail(recipient.Email, sender.Username, what, link, transfer.ExpiresAt))
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.TransferToResponse(transfer), "Transfer offered successfully")
+}
+
+// GetTransfersHandler lists the transfers the caller received, or sent with ?direction=outgoing
+func (h *TransferHandler) GetTransfersHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	incoming := true
+	switch r.URL.Query().Get("direction") {
+	case "", "incoming":
+	case "outgoing":
+		incoming = false
+	default:
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	page, pageSize := paginationParams(r)
+	transfers, total, err := h.repo.List(userID, incoming, page, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(dto.TransfersToResponse(transfers), "Transfers retrieved successfully"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetTransferHandler returns a transfer to either party. The recipient also sees which resources
+// would conflict with URLs they already have.
+func (h *TransferHandler) GetTransferHandler(w http.ResponseWriter, r *http.Request) {
+	transfer, userID, ok := h.routeTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	response := dto.TransferToResponse(transfer)
+	if userID == transfer.ToUserID && response.Pending {
+		conflicts, err := h.repo.Conflicts(transfer)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		response.Conflicts = conflicts
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, response, "Transfer retrieved successfully")
+}
+
+// AcceptTransferHandler moves the resources to the recipient. With conflicting URLs the transfer
+// is refused unless on_conflict is skip, which leaves those resources with the sender.
+func (h *TransferHandler) AcceptTransferHandler(w http.ResponseWriter, r *http.Request) {
+	transfer, userID, ok := h.routeTransfer(w, r)
+	if !ok {
+		return
+	}
+	if userID != transfer.ToUserID {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return
+	}
+
+	// The options are optional, so an empty body (chunked ones included) means the defaults
+	var acceptReq dto.AcceptTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&acceptReq); err != nil && !errors.Is(err, io.EOF) {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	switch acceptReq.OnConflict {
+	case "":
+		acceptReq.OnConflict = models.TransferConflictFail
+	case models.TransferConflictFail, models.TransferConflictSkip:
+	default:
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidTransferConflict)
+		return
+	}
+
+	if err := h.repo.Accept(transfer, acceptReq.OnConflict); err != nil {
+		var conflictErr *repository.TransferConflictError
+		switch {
+		case errors.As(err, &conflictErr):
+			dto.WriteJSON(w, http.StatusConflict, dto.Response{
+				Success: false,
+				Error:   err.Error(),
+				Data:    conflictErr.Conflicts,
+			})
+		case errors.Is(err, models.ErrTransferNotPending):
+			dto.WriteError(w, http.StatusConflict, err)
+		default:
+			dto.WriteError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.TransferToResponse(transfer), "Transfer accepted successfully")
+}
+
+// DeclineTransferHandler lets the recipient turn a transfer down
+func (h *TransferHandler) DeclineTransferHandler(w http.ResponseWriter, r *http.Request) {
+	transfer, userID, ok := h.routeTransfer(w, r)
+	if !ok {
+		return
+	}
+	if userID != transfer.ToUserID {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return
+	}
+	h.respond(w, transfer, models.TransferStatusDeclined, "Transfer declined successfully")
+}
+
+// CancelTransferHandler lets the sender withdraw a transfer that has not been answered
+func (h *TransferHandler) CancelTransferHandler(w http.ResponseWriter, r *http.Request) {
+	transfer, userID, ok := h.routeTransfer(w, r)
+	if !ok {
+		return
+	}
+	if userID != transfer.FromUserID {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return
+	}
+	h.respond(w, transfer, models.TransferStatusCancelled, "Transfer cancelled successfully")
+}
+
+func (h *TransferHandler) respond(w http.ResponseWriter, transfer *models.ResourceTransfer, status models.TransferStatus, message string) {
+	if err := h.repo.Respond(transfer, status); err != nil {
+		if errors.Is(err, models.ErrTransferNotPending) {
+			dto.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.TransferToResponse(transfer), message)
+}
+
+// routeTransfer loads the transfer in the route. Anyone but the two parties gets a 404.
+func (h *TransferHandler) routeTransfer(w http.ResponseWriter, r *http.Request) (*models.ResourceTransfer, uint, bool) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, 0, false
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	transferID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, 0, false
+	}
+
+	transfer, err := h.repo.GetByID(uint(transferID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrTransferNotFound)
+			return nil, 0, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, 0, false
+	}
+	if transfer.FromUserID != userID && transfer.ToUserID != userID {
+		dto.WriteError(w, http.StatusNotFound, models.ErrTransferNotFound)
+		return nil, 0, false
+	}
+	return transfer, userID, true
+}
+
+// uniqueIDs drops duplicate and zero IDs, keeping the original order
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"devlink/internal/dto"
+	"devlink/internal/mailer"
 	"devlink/internal/middleware"
 	"devlink/internal/models"
+	"devlink/internal/password"
 	"devlink/internal/repository"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -14,12 +17,20 @@ import (
 )
 
 type UserHandler struct {
-	repo *repository.UserRepository
+	repo          *repository.UserRepository
+	oneTimeTokens *repository.OneTimeTokenRepository
+	tokens        *repository.TokenRepository
+	hasher        *password.Hasher
+	mailer        mailer.Mailer
 }
 
-func NewUserHandler(userRepository *repository.UserRepository) *UserHandler {
+func NewUserHandler(userRepository *repository.UserRepository, oneTimeTokenRepository *repository.OneTimeTokenRepository, tokenRepository *repository.TokenRepository, hasher *password.Hasher, m mailer.Mailer) *UserHandler {
 	return &UserHandler{
-		repo: userRepository,
+		repo:          userRepository,
+		oneTimeTokens: oneTimeTokenRepository,
+		tokens:        tokenRepository,
+		hasher:        hasher,
+		mailer:        m,
 	}
 }
 
@@ -37,6 +48,12 @@ func (h *UserHandler) GetUserByIDHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Email addresses are only shown to the account owner and moderators
+	if current, ok := middleware.GetCurrentUser(r); !ok || (current.ID != user.ID && !current.HasRole(models.RoleModerator)) {
+		dto.WriteSuccess(w, http.StatusOK, dto.UserToPublicResponse(user), "User retrieved successfully")
+		return
+	}
+
 	dto.WriteSuccess(w, http.StatusOK, dto.UserToResponse(user), "User retrieved successfully")
 }
 
@@ -72,7 +89,7 @@ func (h *UserHandler) GetAllUsersHandler(w http.ResponseWriter, r *http.Request)
 
 func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	if !middleware.IsUserSelf(r, vars["id"]) {
+	if !middleware.IsUserSelfOrAdmin(r, vars["id"]) {
 		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
 		return
 	}
@@ -96,15 +113,33 @@ func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Admins may correct someone else's profile but never set their password
+	if updateReq.Password != "" && !middleware.IsUserSelf(r, vars["id"]) {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return
+	}
+
 	// Update fields if provided
 	if updateReq.Username != "" {
 		user.Username = updateReq.Username
 	}
-	if updateReq.Email != "" {
-		user.Email = updateReq.Email
-	}
-	if updateReq.Password != "" {
-		user.Password = updateReq.Password
+
+	// A new email only replaces the current one once it has been confirmed
+	emailChanged := updateReq.Email != "" && updateReq.Email != user.Email
+	if emailChanged {
+		pending := models.User{Email: updateReq.Email}
+		if err := pending.ValidateEmail(); err != nil {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		if existingUser, _ := h.repo.GetByEmail(pending.Email); existingUser != nil {
+			dto.WriteError(w, http.StatusConflict, models.ErrEmailExists)
+			return
+		}
+		user.PendingEmail = pending.Email
+	} else if updateReq.Email != "" {
+		// Asking for the current address again cancels a pending change
+		user.PendingEmail = ""
 	}
 
 	// Validate updated fields
@@ -112,13 +147,8 @@ func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request)
 		dto.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
-	if err := user.ValidateEmail(); err != nil {
-		dto.WriteError(w, http.StatusBadRequest, err)
-		return
-	}
 	if updateReq.Password != "" {
-		if err := user.ValidatePassword(); err != nil {
-			dto.WriteError(w, http.StatusBadRequest, err)
+		if !h.changePassword(w, user, updateReq) {
 			return
 		}
 	}
@@ -128,12 +158,66 @@ func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Whoever knew the old password must not stay signed in elsewhere
+	if updateReq.Password != "" {
+		claims, _ := middleware.GetUserClaims(r)
+		familyID, _ := claims["fid"].(string)
+		if err := h.tokens.RevokeAllForUserExcept(user.ID, familyID); err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if emailChanged {
+		if err := sendVerificationEmail(h.oneTimeTokens, h.mailer, user.ID, user.PendingEmail); err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		dto.WriteSuccess(w, http.StatusOK, dto.UserToResponse(user), "User updated successfully. Check your new email address to confirm the change")
+		return
+	}
+
 	dto.WriteSuccess(w, http.StatusOK, dto.UserToResponse(user), "User updated successfully")
 }
 
+// changePassword checks the current password and stores a hash of the new one on user.
+// Accounts created through an identity provider have no password yet and can set one directly.
+func (h *UserHandler) changePassword(w http.ResponseWriter, user *models.User, updateReq dto.UpdateUserRequest) bool {
+	if user.HasPassword() {
+		if updateReq.CurrentPassword == "" {
+			dto.WriteError(w, http.StatusBadRequest, models.ErrCurrentPasswordRequired)
+			return false
+		}
+		valid, err := h.hasher.Verify(updateReq.CurrentPassword, user.Password)
+		if err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return false
+		}
+		if !valid {
+			dto.WriteError(w, http.StatusForbidden, models.ErrInvalidCurrentPassword)
+			return false
+		}
+	}
+
+	candidate := models.User{Password: updateReq.Password}
+	if err := candidate.ValidatePassword(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return false
+	}
+
+	hashedPassword, err := h.hasher.Hash(updateReq.Password)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	user.Password = hashedPassword
+	user.PasswordResetRequired = false
+	return true
+}
+
 func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	if !middleware.IsUserSelf(r, vars["id"]) {
+	if !middleware.IsUserSelfOrAdmin(r, vars["id"]) {
 		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
 		return
 	}
@@ -144,7 +228,7 @@ func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	_, err = h.repo.GetByID(uint(userID))
+	user, err := h.repo.GetByID(uint(userID))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			dto.WriteError(w, http.StatusNotFound, err)
@@ -154,7 +238,22 @@ func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Someone has to be left to run the instance
+	if user.HasRole(models.RoleAdmin) {
+		if admins, err := h.repo.CountActiveAdmins(); err != nil {
+			dto.WriteError(w, http.StatusInternalServerError, err)
+			return
+		} else if admins <= 1 {
+			dto.WriteError(w, http.StatusConflict, models.ErrLastAdmin)
+			return
+		}
+	}
+
 	if err := h.repo.DeleteUser(uint(userID)); err != nil {
+		if errors.Is(err, models.ErrSoleWorkspaceOwner) {
+			dto.WriteError(w, http.StatusConflict, err)
+			return
+		}
 		dto.WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"devlink/internal/config"
+	"devlink/internal/dto"
+	"devlink/internal/mailer"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/repository"
+	"devlink/internal/utils"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+type VerificationHandler struct {
+	repo          *repository.UserRepository
+	oneTimeTokens *repository.OneTimeTokenRepository
+	mailer        mailer.Mailer
+}
+
+func NewVerificationHandler(userRepository *repository.UserRepository, oneTimeTokenRepository *repository.OneTimeTokenRepository, m mailer.Mailer) *VerificationHandler {
+	return &VerificationHandler{
+		repo:          userRepository,
+		oneTimeTokens: oneTimeTokenRepository,
+		mailer:        m,
+	}
+}
+
+// VerifyEmailHandler confirms either the current address or a pending address change
+func (h *VerificationHandler) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var verifyReq dto.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&verifyReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if verifyReq.Token == "" {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+
+	token, err := h.oneTimeTokens.Peek(utils.HashToken(verifyReq.Token), models.TokenPurposeEmailVerification)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidOneTimeToken) {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err := h.repo.GetByID(token.UserID)
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidOneTimeToken)
+		return
+	}
+
+	switch {
+	case token.Email == user.PendingEmail && user.PendingEmail != "":
+		// The new address may have been registered by someone else since the change was requested
+		if existingUser, _ := h.repo.GetByEmail(user.PendingEmail); existingUser != nil {
+			dto.WriteError(w, http.StatusConflict, models.ErrEmailExists)
+			return
+		}
+		user.Email = user.PendingEmail
+		user.PendingEmail = ""
+	case token.Email == user.Email:
+	default:
+		// The token was sent to an address the account no longer uses
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidOneTimeToken)
+		return
+	}
+
+	if _, err := h.oneTimeTokens.Consume(token.TokenHash, models.TokenPurposeEmailVerification); err != nil {
+		if errors.Is(err, models.ErrInvalidOneTimeToken) {
+			dto.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := h.repo.UpdateUser(user); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.UserToResponse(user), "Email verified successfully")
+}
+
+// ResendVerificationHandler sends a fresh link for the pending address, or for the current one if unverified
+func (h *VerificationHandler) ResendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	user, err := h.repo.GetByID(userID)
+	if err != nil {
+		dto.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	email := user.PendingEmail
+	if email == "" {
+		if user.IsEmailVerified() {
+			dto.WriteError(w, http.StatusBadRequest, models.ErrEmailVerified)
+			return
+		}
+		email = user.Email
+	}
+
+	if err := sendVerificationEmail(h.oneTimeTokens, h.mailer, user.ID, email); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Verification email sent")
+}
+
+// sendVerificationEmail issues a verification token for email and mails the link to that address
+func sendVerificationEmail(repo *repository.OneTimeTokenRepository, m mailer.Mailer, userID uint, email string) error {
+	ttl := config.GetEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+	token, err := issueOneTimeToken(repo, userID, models.TokenPurposeEmailVerification, email, ttl)
+	if err != nil {
+		return err
+	}
+	mailer.SendAsync(m, mailer.VerificationEmail(email, appLink("/verify-email", token), ttl))
+	return nil
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"devlink/internal/utils"
+	"encoding/json"
+	"net/http"
+)
+
+// JWKSHandler publishes the public signing keys in the standard JWKS shape so other
+// services can verify DevLink access tokens without sharing a secret
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(map[string][]utils.JSONWebKey{
+		"keys": utils.ActiveKeyring().JWKS(),
+	})
+}
@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"devlink/internal/dto"
+	"devlink/internal/mailer"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"devlink/internal/policy"
+	"devlink/internal/repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type WorkspaceHandler struct {
+	repo   *repository.WorkspaceRepository
+	users  *repository.UserRepository
+	policy *policy.Policy
+	mailer mailer.Mailer
+}
+
+func NewWorkspaceHandler(workspaceRepository *repository.WorkspaceRepository, userRepository *repository.UserRepository, resourcePolicy *policy.Policy, m mailer.Mailer) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		repo:   workspaceRepository,
+		users:  userRepository,
+		policy: resourcePolicy,
+		mailer: m,
+	}
+}
+
+// CreateWorkspaceHandler creates a workspace with the caller as its owner
+func (h *WorkspaceHandler) CreateWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	var createReq dto.CreateWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	workspace := &models.Workspace{
+		Name:        createReq.Name,
+		Description: createReq.Description,
+		CreatedByID: userID,
+	}
+	if err := workspace.Validate(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.repo.CreateWorkspace(workspace); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.WorkspaceToResponse(workspace, models.WorkspaceRoleOwner), "Workspace created successfully")
+}
+
+// GetWorkspacesHandler lists the workspaces the caller is a member of
+func (h *WorkspaceHandler) GetWorkspacesHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeMemberships(w, r, true, "Workspaces retrieved successfully")
+}
+
+// GetWorkspaceInvitationsHandler lists the workspaces the caller was invited to and has not answered yet
+func (h *WorkspaceHandler) GetWorkspaceInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	h.writeMemberships(w, r, false, "Workspace invitations retrieved successfully")
+}
+
+func (h *WorkspaceHandler) GetWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	workspace, _, role, ok := h.memberWorkspace(w, r, models.WorkspaceRoleViewer)
+	if !ok {
+		return
+	}
+	dto.WriteSuccess(w, http.StatusOK, dto.WorkspaceToResponse(workspace, role), "Workspace retrieved successfully")
+}
+
+func (h *WorkspaceHandler) UpdateWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	workspace, _, role, ok := h.memberWorkspace(w, r, models.WorkspaceRoleOwner)
+	if !ok {
+		return
+	}
+
+	var updateReq dto.UpdateWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Update fields if provided
+	if updateReq.Name != nil {
+		workspace.Name = *updateReq.Name
+	}
+	if updateReq.Description != nil {
+		workspace.Description = *updateReq.Description
+	}
+	if err := workspace.Validate(); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.repo.UpdateWorkspace(workspace); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.WorkspaceToResponse(workspace, role), "Workspace updated successfully")
+}
+
+// DeleteWorkspaceHandler deletes a workspace and its collections. Its resources are not lost:
+// they go back to the personal libraries of whoever created them.
+func (h *WorkspaceHandler) DeleteWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	workspace, _, _, ok := h.memberWorkspace(w, r, models.WorkspaceRoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteWorkspace(workspace.ID); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, "Workspace deleted successfully")
+}
+
+// GetWorkspaceMembersHandler lists members and pending invitations
+func (h *WorkspaceHandler) GetWorkspaceMembersHandler(w http.ResponseWriter, r *http.Request) {
+	workspace, _, _, ok := h.memberWorkspace(w, r, models.WorkspaceRoleViewer)
+	if !ok {
+		return
+	}
+
+	members, err := h.repo.GetMembers(workspace.ID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]dto.WorkspaceMemberResponse, len(members))
+	for i, member := range members {
+		responses[i] = dto.WorkspaceMemberToResponse(&member.WorkspaceMember, member.Username)
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, responses, "Workspace members retrieved successfully")
+}
+
+// InviteWorkspaceMemberHandler invites an existing user, found by username or email. They get
+// access once they accept.
+func (h *WorkspaceHandler) InviteWorkspaceMemberHandler(w http.ResponseWriter, r *http.Request) {
+	workspace, userID, _, ok := h.memberWorkspace(w, r, models.WorkspaceRoleOwner)
+	if !ok {
+		return
+	}
+
+	var inviteReq dto.InviteWorkspaceMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&inviteReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if inviteReq.Role == "" {
+		inviteReq.Role = models.WorkspaceRoleViewer
+	}
+	if !inviteReq.Role.IsValid() {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidWorkspaceRole)
+		return
+	}
+
+	var invitee *models.User
+	var err error
+	switch {
+	case strings.TrimSpace(inviteReq.Username) != "":
+		invitee, err = h.users.GetByUsername(strings.TrimSpace(inviteReq.Username))
+	case strings.TrimSpace(inviteReq.Email) != "":
+		invitee, err = h.users.GetByEmail(strings.TrimSpace(inviteReq.Email))
+	default:
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidRequest)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrUserNotFound)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	member := &models.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      invitee.ID,
+		Role:        inviteReq.Role,
+		InvitedByID: &userID,
+	}
+	if err := h.repo.AddMember(member); err != nil {
+		if errors.Is(err, models.ErrWorkspaceMemberExists) {
+			dto.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if inviter, ok := middleware.GetCurrentUser(r); ok {
+		link := appURL("/workspaces/invitations")
+		mailer.SendAsync(h.mailer, mailer.WorkspaceInvitationEmail(invitee.Email, inviter.Username, workspace.Name, string(member.Role), link))
+	}
+
+	dto.WriteSuccess(w, http.StatusCreated, dto.WorkspaceMemberToResponse(member, invitee.Username), "Invitation sent successfully")
+}
+
+// UpdateWorkspaceMemberHandler changes a member's role
+func (h *WorkspaceHandler) UpdateWorkspaceMemberHandler(w http.ResponseWriter, r *http.Request) {
+	workspace, _, _, ok := h.memberWorkspace(w, r, models.WorkspaceRoleOwner)
+	if !ok {
+		return
+	}
+	member, ok := h.routeMember(w, r, workspace.ID)
+	if !ok {
+		return
+	}
+
+	var updateReq dto.UpdateWorkspaceMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !updateReq.Role.IsValid() {
+		dto.WriteError(w, http.StatusBadRequest, models.ErrInvalidWorkspaceRole)
+		return
+	}
+
+	if err := h.repo.UpdateMemberRole(member, updateReq.Role); err != nil {
+		if errors.Is(err, models.ErrLastWorkspaceOwner) {
+			dto.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err := h.users.GetByID(member.UserID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.WorkspaceMemberToResponse(member, user.Username), "Member role updated successfully")
+}
+
+// RemoveWorkspaceMemberHandler removes a member or cancels a pending invitation
+func (h *WorkspaceHandler) RemoveWorkspaceMemberHandler(w http.ResponseWriter, r *http.Request) {
+	workspace, _, _, ok := h.memberWorkspace(w, r, models.WorkspaceRoleOwner)
+	if !ok {
+		return
+	}
+	member, ok := h.routeMember(w, r, workspace.ID)
+	if !ok {
+		return
+	}
+
+	h.removeMember(w, member, "Member removed successfully")
+}
+
+// AcceptWorkspaceInvitationHandler accepts the caller's pending invitation to the workspace
+func (h *WorkspaceHandler) AcceptWorkspaceInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	member, ok := h.ownMembership(w, r)
+	if !ok {
+		return
+	}
+	if member.IsActive() {
+		dto.WriteError(w, http.StatusNotFound, models.ErrWorkspaceInvitationNotFound)
+		return
+	}
+
+	if err := h.repo.AcceptInvitation(member); err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	workspace, err := h.repo.GetByID(member.WorkspaceID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, dto.WorkspaceToResponse(workspace, member.Role), "You joined the workspace")
+}
+
+// LeaveWorkspaceHandler removes the caller from the workspace, or declines a pending invitation
+func (h *WorkspaceHandler) LeaveWorkspaceHandler(w http.ResponseWriter, r *http.Request) {
+	member, ok := h.ownMembership(w, r)
+	if !ok {
+		return
+	}
+
+	if member.IsActive() {
+		h.removeMember(w, member, "You left the workspace")
+		return
+	}
+	h.removeMember(w, member, "Invitation declined")
+}
+
+func (h *WorkspaceHandler) removeMember(w http.ResponseWriter, member *models.WorkspaceMember, message string) {
+	if err := h.repo.RemoveMember(member); err != nil {
+		if errors.Is(err, models.ErrLastWorkspaceOwner) {
+			dto.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	dto.WriteSuccess(w, http.StatusOK, nil, message)
+}
+
+func (h *WorkspaceHandler) writeMemberships(w http.ResponseWriter, r *http.Request, accepted bool, message string) {
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	page, pageSize := paginationParams(r)
+	memberships, total, err := h.repo.GetMemberships(userID, accepted, page, pageSize)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]dto.WorkspaceResponse, len(memberships))
+	for i, membership := range memberships {
+		responses[i] = dto.WorkspaceToResponse(&membership.Workspace, membership.Role)
+	}
+
+	response := dto.PaginatedResponse{
+		Response: dto.NewSuccessResponse(responses, message),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    int(total),
+	}
+
+	dto.WriteJSON(w, http.StatusOK, response)
+}
+
+// memberWorkspace loads the workspace in the route if the caller holds at least role in it
+func (h *WorkspaceHandler) memberWorkspace(w http.ResponseWriter, r *http.Request, role models.WorkspaceRole) (*models.Workspace, uint, models.WorkspaceRole, bool) {
+	workspaceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, 0, "", false
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, 0, "", false
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	current, ok := workspaceAccess(w, h.policy, userID, uint(workspaceID), role)
+	if !ok {
+		return nil, 0, "", false
+	}
+
+	workspace, err := h.repo.GetByID(uint(workspaceID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrWorkspaceNotFound)
+			return nil, 0, "", false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, 0, "", false
+	}
+	return workspace, userID, current, true
+}
+
+// routeMember loads the membership of the user in the route
+func (h *WorkspaceHandler) routeMember(w http.ResponseWriter, r *http.Request, workspaceID uint) (*models.WorkspaceMember, bool) {
+	memberID, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	member, err := h.repo.GetMember(workspaceID, uint(memberID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrWorkspaceMemberNotFound)
+			return nil, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return member, true
+}
+
+// ownMembership loads the caller's membership of the workspace in the route, accepted or not
+func (h *WorkspaceHandler) ownMembership(w http.ResponseWriter, r *http.Request) (*models.WorkspaceMember, bool) {
+	workspaceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		dto.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	// Get user ID from JWT
+	claims, ok := middleware.GetUserClaims(r)
+	if !ok {
+		dto.WriteError(w, http.StatusUnauthorized, models.ErrInvalidCredentials)
+		return nil, false
+	}
+	userID := uint(claims["user_id"].(float64))
+
+	member, err := h.repo.GetMember(uint(workspaceID), userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dto.WriteError(w, http.StatusNotFound, models.ErrWorkspaceNotFound)
+			return nil, false
+		}
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return member, true
+}
+
+// workspaceAccess checks the user holds at least role in the workspace and returns their role.
+// Non-members get a 404 so workspace IDs cannot be probed.
+func workspaceAccess(w http.ResponseWriter, access *policy.Policy, userID, workspaceID uint, role models.WorkspaceRole) (models.WorkspaceRole, bool) {
+	current, err := access.WorkspaceRole(workspaceID, userID)
+	if err != nil {
+		dto.WriteError(w, http.StatusInternalServerError, err)
+		return "", false
+	}
+	if current == "" {
+		dto.WriteError(w, http.StatusNotFound, models.ErrWorkspaceNotFound)
+		return "", false
+	}
+	if !current.AtLeast(role) {
+		dto.WriteError(w, http.StatusForbidden, models.ErrForbidden)
+		return "", false
+	}
+	return current, true
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"log"
+	"strings"
+
+	"devlink/internal/config"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers outgoing email. Backends are chosen with the MAILER env var.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// NewFromEnv builds the mailer selected by MAILER: "smtp", "file" or "log" (the default)
+func NewFromEnv() Mailer {
+	from := config.GetEnv("MAIL_FROM", "DevLink <no-reply@devlink.local>")
+
+	switch strings.ToLower(config.GetEnv("MAILER", "log")) {
+	case "smtp":
+		return NewSMTPMailer(
+			config.GetEnv("SMTP_HOST", "localhost"),
+			config.GetEnv("SMTP_PORT", "587"),
+			config.GetEnv("SMTP_USERNAME", ""),
+			config.GetEnv("SMTP_PASSWORD", ""),
+			from,
+		)
+	case "file":
+		return NewFileMailer(config.GetEnv("MAIL_DIR", "mail"), from)
+	case "log":
+		return NewLogMailer(from)
+	default:
+		log.Printf("Unknown MAILER %q, falling back to log", config.GetEnv("MAILER", ""))
+		return NewLogMailer(from)
+	}
+}
+
+// SendAsync delivers a message in the background and logs failures.
+// Handlers use it so response timing does not depend on the mail server.
+func SendAsync(m Mailer, msg Message) {
+	go func() {
+		if err := m.Send(msg); err != nil {
+			log.Printf("Failed to send email %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
@@ -0,0 +1,117 @@
+package mailer
+
+import (
+	"fmt"
+	"time"
+)
+
+// VerificationEmail builds the message that asks the user to confirm an email address
+func VerificationEmail(to, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Confirm your DevLink email address",
+		Body: fmt.Sprintf(`Please confirm that this is your email address by opening the link below.
+It expires in %s.
+
+%s
+
+If you did not create a DevLink account or change its email, you can ignore this email.
+`, ttl, link),
+	}
+}
+
+// PasswordResetEmail builds the message that carries a password reset link
+func PasswordResetEmail(to, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Reset your DevLink password",
+		Body: fmt.Sprintf(`Someone asked to reset the password for your DevLink account.
+
+Use the link below to choose a new password. It expires in %s and can only be used once.
+
+%s
+
+If you did not ask for this, you can ignore this email.
+`, ttl, link),
+	}
+}
+
+// MagicLinkEmail builds the message that carries a passwordless sign-in link
+func MagicLinkEmail(to, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: "Your DevLink sign-in link",
+		Body: fmt.Sprintf(`Use the link below to sign in to DevLink. It expires in %s and can only be used once.
+
+%s
+
+If you did not ask to sign in, you can ignore this email. Nobody can sign in without the link.
+`, ttl, link),
+	}
+}
+
+// InvitationEmail invites someone to register and carries the invitation code in the link
+func InvitationEmail(to, inviter, link string, expiresAt time.Time) Message {
+	return Message{
+		To:      to,
+		Subject: inviter + " invited you to DevLink",
+		Body: fmt.Sprintf(`%s invited you to join DevLink, a place to save and organise coding resources.
+
+Use the link below to create your account. The invitation is only valid for this email address
+and expires on %s.
+
+%s
+
+If you were not expecting this, you can ignore this email.
+`, inviter, expiresAt.UTC().Format(time.RFC1123), link),
+	}
+}
+
+// AccountLockedEmail tells the user their account was locked and carries a link to unlock it early
+func AccountLockedEmail(to, link string, until time.Time) Message {
+	return Message{
+		To:      to,
+		Subject: "Your DevLink account has been locked",
+		Body: fmt.Sprintf(`We locked your DevLink account after too many failed sign-in attempts.
+It unlocks automatically at %s.
+
+If it was you, use the link below to unlock it now. The link can only be used once.
+
+%s
+
+If it was not you, someone may be guessing your password. Consider changing it once you are back in.
+`, until.UTC().Format(time.RFC1123), link),
+	}
+}
+
+// WorkspaceInvitationEmail tells an existing user they were invited to a workspace
+func WorkspaceInvitationEmail(to, inviter, workspace, role, link string) Message {
+	return Message{
+		To:      to,
+		Subject: inviter + " invited you to " + workspace + " on DevLink",
+		Body: fmt.Sprintf(`%s invited you to join the workspace "%s" as %s.
+
+Open your pending invitations to accept or decline:
+
+%s
+
+Until you accept, you will not see the workspace or its resources.
+`, inviter, workspace, role, link),
+	}
+}
+
+// ResourceTransferEmail tells a user someone wants to hand resources over to them
+func ResourceTransferEmail(to, sender, what, link string, expiresAt time.Time) Message {
+	return Message{
+		To:      to,
+		Subject: sender + " wants to transfer resources to you on DevLink",
+		Body: fmt.Sprintf(`%s wants to transfer %s to you.
+
+Review the transfer to accept or decline it:
+
+%s
+
+Nothing moves until you accept. The offer expires on %s.
+`, sender, what, link, expiresAt.UTC().Format(time.RFC1123)),
+	}
+}
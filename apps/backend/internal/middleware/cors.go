@@ -1,6 +1,9 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 type CORSMiddleware struct {
 	allowedOrigins []string
@@ -20,6 +23,13 @@ func (c *CORSMiddleware) CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
 
+		// Requests without an Origin are not cross-origin (CLI clients, top-level
+		// navigations such as identity provider redirects), so CORS does not apply
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		// Check if the origin is allowed
 		allowed := false
 		for _, allowedOrigin := range c.allowedOrigins {
@@ -35,8 +45,8 @@ func (c *CORSMiddleware) CORS(next http.Handler) http.Handler {
 			return
 		}
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.allowedMethods, ", "))
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.allowedHeaders, ", "))
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
 
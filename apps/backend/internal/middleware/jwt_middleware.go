@@ -2,51 +2,250 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"devlink/internal/models"
+	"devlink/internal/repository"
 	"devlink/internal/utils"
 
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 // userCtxKeyType is a custom type to avoid context key collisions
 type userCtxKeyType string
 
-const userCtxKey userCtxKeyType = "user"
+const (
+	userCtxKey        userCtxKeyType = "user"
+	currentUserCtxKey userCtxKeyType = "current_user"
+)
+
+// tokenTypePAT marks claims that were built from a personal access token
+const tokenTypePAT = "pat"
 
-// JWTAuthMiddleware validates JWT and attaches user info to the request context
-func JWTAuthMiddleware(next http.Handler) http.Handler {
+// AuthMiddleware authenticates requests and checks tokens against the revocation list
+type AuthMiddleware struct {
+	tokens       *repository.TokenRepository
+	accessTokens *repository.AccessTokenRepository
+	users        *repository.UserRepository
+}
+
+func NewAuthMiddleware(tokenRepository *repository.TokenRepository, accessTokenRepository *repository.AccessTokenRepository, userRepository *repository.UserRepository) *AuthMiddleware {
+	return &AuthMiddleware{
+		tokens:       tokenRepository,
+		accessTokens: accessTokenRepository,
+		users:        userRepository,
+	}
+}
+
+// JWTAuthMiddleware validates a JWT or personal access token from the Authorization header, or the
+// session cookie in cookie mode, and attaches user info to the request context
+func (a *AuthMiddleware) JWTAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		header := r.Header.Get("Authorization")
-		if header == "" || !strings.HasPrefix(header, "Bearer ") {
+		var tokenString string
+		var fromCookie bool
+		if header := r.Header.Get("Authorization"); header != "" {
+			if !strings.HasPrefix(header, "Bearer ") {
+				http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+				return
+			}
+			tokenString = strings.TrimPrefix(header, "Bearer ")
+		} else if cookie, err := r.Cookie(SessionCookie); err == nil && cookie.Value != "" {
+			// Browsers attach cookies to cross-site requests too, so writes must prove
+			// they came from our frontend
+			if !isSafeMethod(r.Method) && !ValidCSRF(r) {
+				http.Error(w, models.ErrInvalidCSRFToken.Error(), http.StatusForbidden)
+				return
+			}
+			tokenString, fromCookie = cookie.Value, true
+		} else {
 			http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
 			return
 		}
-		tokenString := strings.TrimPrefix(header, "Bearer ")
 
-		claims := jwt.MapClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return utils.GetJWTSecret(), nil
-		})
-		if err != nil || !token.Valid {
+		var claims jwt.MapClaims
+		var status int
+		if strings.HasPrefix(tokenString, models.PersonalAccessTokenPrefix) && !fromCookie {
+			claims, status = a.authenticatePAT(tokenString)
+		} else {
+			claims, status = a.authenticateJWT(tokenString, ClientIP(r))
+		}
+		switch status {
+		case http.StatusOK:
+		case http.StatusInternalServerError:
+			http.Error(w, "Failed to verify token", http.StatusInternalServerError)
+			return
+		default:
+			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+
+		// Load the account on every request so suspensions and role changes apply immediately
+		userID, _ := claims["user_id"].(float64)
+		user, err := a.users.GetByID(uint(userID))
+		if err != nil {
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
 		}
+		if user.IsSuspended() {
+			http.Error(w, models.ErrAccountSuspended.Error(), http.StatusForbidden)
+			return
+		}
+		// A forced reset revokes the user's access tokens, but one could still be created with an
+		// access JWT issued before it
+		if claims["token_type"] == tokenTypePAT && user.PasswordResetRequired {
+			http.Error(w, models.ErrPasswordResetRequired.Error(), http.StatusForbidden)
+			return
+		}
 
-		// Attach claims to context for use in handlers
+		// Attach claims and the user to context for use in handlers
 		ctx := context.WithValue(r.Context(), userCtxKey, claims)
+		ctx = context.WithValue(ctx, currentUserCtxKey, user)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+func (a *AuthMiddleware) authenticateJWT(tokenString, ip string) (jwt.MapClaims, int) {
+	claims, err := utils.ParseJWT(tokenString, utils.TokenTypeAccess)
+	if err != nil {
+		return nil, http.StatusUnauthorized
+	}
+
+	// Reject tokens revoked by logout before they expire
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, http.StatusUnauthorized
+	}
+	revoked, err := a.tokens.IsJTIRevoked(jti)
+	if err != nil {
+		return nil, http.StatusInternalServerError
+	}
+	if revoked {
+		return nil, http.StatusUnauthorized
+	}
+
+	// Reject tokens whose session was signed out, e.g. from another device
+	familyID, _ := claims["fid"].(string)
+	session, err := a.tokens.GetSessionByFamily(familyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, http.StatusUnauthorized
+		}
+		return nil, http.StatusInternalServerError
+	}
+	now := time.Now()
+	if !session.IsActive(now) {
+		return nil, http.StatusUnauthorized
+	}
+
+	// Only record activity once a minute to avoid a write on every request
+	if now.Sub(session.LastSeenAt) > time.Minute || session.IP != ip {
+		a.tokens.TouchSession(session.ID, ip, now)
+	}
+	claims["sid"] = float64(session.ID)
+	return claims, http.StatusOK
+}
+
+func (a *AuthMiddleware) authenticatePAT(tokenString string) (jwt.MapClaims, int) {
+	token, err := a.accessTokens.GetByHash(utils.HashToken(tokenString))
+	if err != nil {
+		return nil, http.StatusUnauthorized
+	}
+	now := time.Now()
+	if !token.IsActive(now) {
+		return nil, http.StatusUnauthorized
+	}
+
+	// Only record usage once a minute to avoid a write on every request
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > time.Minute {
+		a.accessTokens.TouchLastUsed(token.ID, now)
+	}
+
+	// Mirror the JWT claim shape so handlers don't need to care which kind of token was used
+	return jwt.MapClaims{
+		"user_id":    float64(token.UserID),
+		"token_type": tokenTypePAT,
+		"token_id":   float64(token.ID),
+		"scope":      token.Scopes,
+	}, http.StatusOK
+}
+
+// RequireScope rejects requests whose token was not granted the given scope
+func RequireScope(scope string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetUserClaims(r)
+		if !ok {
+			http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+			return
+		}
+		if !HasScope(claims, scope) {
+			http.Error(w, models.ErrInsufficientScope.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireRole rejects requests from users whose role does not grant at least the given role
+func RequireRole(role models.Role, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetCurrentUser(r)
+		if !ok {
+			http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+			return
+		}
+		if !user.HasRole(role) {
+			http.Error(w, models.ErrForbidden.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HasScope reports whether the claims grant a scope. Session tokens from login grant every scope.
+func HasScope(claims jwt.MapClaims, scope string) bool {
+	if !IsPersonalAccessToken(claims) {
+		return true
+	}
+	granted, _ := claims["scope"].(string)
+	for _, s := range strings.Fields(granted) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPersonalAccessToken reports whether the claims came from a personal access token
+func IsPersonalAccessToken(claims jwt.MapClaims) bool {
+	tokenType, _ := claims["token_type"].(string)
+	return tokenType == tokenTypePAT
+}
+
 // GetUserClaims extracts JWT claims from context
 func GetUserClaims(r *http.Request) (jwt.MapClaims, bool) {
 	claims, ok := r.Context().Value(userCtxKey).(jwt.MapClaims)
 	return claims, ok
 }
 
+// GetCurrentUser returns the authenticated user loaded by JWTAuthMiddleware
+func GetCurrentUser(r *http.Request) (*models.User, bool) {
+	user, ok := r.Context().Value(currentUserCtxKey).(*models.User)
+	return user, ok
+}
+
+// IsUserSelfOrAdmin is IsUserSelf that also lets admins act on any account
+func IsUserSelfOrAdmin(r *http.Request, paramID string) bool {
+	if user, ok := GetCurrentUser(r); ok && user.HasRole(models.RoleAdmin) {
+		return true
+	}
+	return IsUserSelf(r, paramID)
+}
+
 // IsUserSelf checks if the user in the JWT matches the user ID in the route param
 func IsUserSelf(r *http.Request, paramID string) bool {
 	claims, ok := GetUserClaims(r)
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"devlink/internal/config"
+	"devlink/internal/utils"
+)
+
+// Cookie session mode lets the web frontend keep tokens out of JavaScript. Clients opt in by
+// sending X-Auth-Mode: cookie when logging in; from then on the access token travels in an
+// HttpOnly cookie, and writes must echo the CSRF cookie in the X-CSRF-Token header.
+const (
+	AuthModeHeader = "X-Auth-Mode"
+	AuthModeCookie = "cookie"
+	CSRFHeader     = "X-CSRF-Token"
+
+	SessionCookie = "devlink_session"
+	RefreshCookie = "devlink_refresh"
+	CSRFCookie    = "devlink_csrf"
+)
+
+// refreshCookiePath limits the refresh token to the endpoints that need it
+const refreshCookiePath = "/users"
+
+// WantsCookieAuth reports whether the client asked for tokens as cookies
+func WantsCookieAuth(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get(AuthModeHeader), AuthModeCookie)
+}
+
+// HasSessionCookies reports whether the request carries cookies from cookie session mode
+func HasSessionCookies(r *http.Request) bool {
+	for _, name := range []string{SessionCookie, RefreshCookie} {
+		if _, err := r.Cookie(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidCSRF checks the double-submit token: the header must match the CSRF cookie
+func ValidCSRF(r *http.Request) bool {
+	cookie, err := r.Cookie(CSRFCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	header := r.Header.Get(CSRFHeader)
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1
+}
+
+// isSafeMethod reports whether a request cannot change state and so needs no CSRF token
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// SetSessionCookies stores a freshly issued token pair and CSRF token as cookies
+func SetSessionCookies(w http.ResponseWriter, accessToken, refreshToken, csrfToken string) {
+	http.SetCookie(w, newCookie(SessionCookie, accessToken, "/", utils.AccessTokenTTL(), true))
+	http.SetCookie(w, newCookie(RefreshCookie, refreshToken, refreshCookiePath, utils.RefreshTokenTTL(), true))
+	// Not HttpOnly: the frontend reads it to echo in X-CSRF-Token
+	http.SetCookie(w, newCookie(CSRFCookie, csrfToken, "/", utils.RefreshTokenTTL(), false))
+}
+
+// ClearSessionCookies removes every cookie set by SetSessionCookies
+func ClearSessionCookies(w http.ResponseWriter) {
+	http.SetCookie(w, newCookie(SessionCookie, "", "/", -1, true))
+	http.SetCookie(w, newCookie(RefreshCookie, "", refreshCookiePath, -1, true))
+	http.SetCookie(w, newCookie(CSRFCookie, "", "/", -1, false))
+}
+
+// newCookie builds a cookie using COOKIE_SECURE, COOKIE_DOMAIN and COOKIE_SAMESITE.
+// A negative ttl deletes the cookie.
+func newCookie(name, value, path string, ttl time.Duration, httpOnly bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   config.GetEnv("COOKIE_DOMAIN", ""),
+		HttpOnly: httpOnly,
+		Secure:   config.GetEnvBool("COOKIE_SECURE", true),
+		SameSite: cookieSameSite(),
+	}
+	if ttl < 0 {
+		cookie.MaxAge = -1
+	} else {
+		cookie.MaxAge = int(ttl.Seconds())
+	}
+	return cookie
+}
+
+func cookieSameSite() http.SameSite {
+	switch strings.ToLower(config.GetEnv("COOKIE_SAMESITE", "lax")) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
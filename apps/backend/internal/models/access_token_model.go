@@ -0,0 +1,82 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Scopes a personal access token can be granted. Session tokens from login carry every scope.
+const (
+	ScopeResourcesRead  = "resources:read"
+	ScopeResourcesWrite = "resources:write"
+	ScopeUsersRead      = "users:read"
+	ScopeUsersWrite     = "users:write"
+	ScopeUsersAdmin     = "users:admin"
+	ScopeAdmin          = "admin" // the /admin API; the user's role is checked as well
+)
+
+var AllScopes = []string{
+	ScopeResourcesRead,
+	ScopeResourcesWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeUsersAdmin,
+	ScopeAdmin,
+}
+
+// PersonalAccessTokenPrefix marks bearer tokens that are personal access tokens rather than JWTs
+const PersonalAccessTokenPrefix = "dlp_"
+
+// PersonalAccessToken is a long-lived, named token for scripts and CLIs.
+// Only a hash of the token is stored; the plain value is shown once on creation.
+type PersonalAccessToken struct {
+	gorm.Model
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Name        string     `json:"name" gorm:"not null"`
+	TokenHash   string     `json:"-" gorm:"not null;uniqueIndex;size:64"`
+	TokenPrefix string     `json:"token_prefix" gorm:"not null;size:16"`
+	Scopes      string     `json:"scopes" gorm:"not null"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+// ScopeList returns the granted scopes
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// IsActive reports whether the token can still be used
+func (t *PersonalAccessToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// ValidateScopes checks that every requested scope is known
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		known := false
+		for _, s := range AllScopes {
+			if scope == s {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return ErrInvalidScope
+		}
+	}
+	return nil
+}
+
+var (
+	ErrInvalidScope      = &ValidationError{Message: "Scopes must be one or more of: " + strings.Join(AllScopes, ", ")}
+	ErrInsufficientScope = &ValidationError{Message: "Token does not have the required scope"}
+	ErrInvalidExpiry     = &ValidationError{Message: "Token expiry must be between 1 and 365 days"}
+	ErrInvalidTokenName  = &ValidationError{Message: "Token name must be 1-100 characters long"}
+	ErrTokenNotSession   = &ValidationError{Message: "Personal access tokens cannot log out; revoke the token instead"}
+)
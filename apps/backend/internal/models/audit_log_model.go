@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Audit actions recorded for security-relevant events
+const (
+	AuditActionAccountLocked   = "account_locked"
+	AuditActionAccountUnlocked = "account_unlocked"
+	AuditActionIPLocked        = "ip_locked"
+	AuditActionUserSuspended   = "user_suspended"
+	AuditActionUserReactivated = "user_reactivated"
+	AuditActionRoleChanged     = "role_changed"
+	AuditActionPasswordReset   = "password_reset_forced"
+)
+
+// AuditLog is an append-only record of a security-relevant event.
+// UserID is nil when the event is not tied to a known account, e.g. an IP lockout.
+type AuditLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    *uint     `json:"user_id" gorm:"index"`
+	ActorID   *uint     `json:"actor_id"` // who performed the action, when it was not the system
+	Action    string    `json:"action" gorm:"not null;size:64;index"`
+	IP        string    `json:"ip" gorm:"size:64"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// CanonicalLink is a URL in canonical form (see urlnorm). Every user's resource for the same page
+// points at the same link, which keeps URLs unique per library and gives instance-wide save counts.
+type CanonicalLink struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	URL       string    `json:"url" gorm:"not null;uniqueIndex"`
+	Host      string    `json:"host" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DuplicateResourceError reports that the library already holds a resource for the same link
+type DuplicateResourceError struct {
+	Err                error
+	ExistingResourceID uint
+}
+
+func (e *DuplicateResourceError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DuplicateResourceError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	ErrInvalidURL            = &ValidationError{Message: "URL must be absolute, like https://example.com"}
+	ErrResourceAlreadySaved  = &ValidationError{Message: "You already saved this link"}
+	ErrWorkspaceAlreadySaved = &ValidationError{Message: "This workspace already has this link"}
+)
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// Collection is a named, manually ordered list of a user's resources. A resource can be
+// in any number of collections. Workspace collections hold that workspace's resources.
+type Collection struct {
+	gorm.Model
+	UserID      uint   `json:"user_id" gorm:"not null;index"`
+	WorkspaceID *uint  `json:"workspace_id" gorm:"index"`
+	Name        string `json:"name" gorm:"not null;size:100"`
+	Description string `json:"description" gorm:"size:500"`
+	Icon        string `json:"icon" gorm:"size:32"`
+}
+
+// CollectionItem places a resource in a collection. Positions run from 0 without gaps.
+type CollectionItem struct {
+	ID           uint      `json:"id" gorm:"primarykey"`
+	CollectionID uint      `json:"collection_id" gorm:"not null;uniqueIndex:idx_collection_item;index:idx_collection_position,priority:1"`
+	ResourceID   uint      `json:"resource_id" gorm:"not null;uniqueIndex:idx_collection_item;index"`
+	Position     int       `json:"position" gorm:"not null;index:idx_collection_position,priority:2"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Validate trims and checks the user-editable fields
+func (c *Collection) Validate() error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Description = strings.TrimSpace(c.Description)
+	c.Icon = strings.TrimSpace(c.Icon)
+	if c.Name == "" || utf8.RuneCountInString(c.Name) > 100 {
+		return ErrInvalidCollectionName
+	}
+	if utf8.RuneCountInString(c.Description) > 500 {
+		return ErrInvalidCollectionDescription
+	}
+	if utf8.RuneCountInString(c.Icon) > 32 {
+		return ErrInvalidCollectionIcon
+	}
+	return nil
+}
+
+var (
+	ErrCollectionNotFound           = &ValidationError{Message: "Collection not found"}
+	ErrInvalidCollectionName        = &ValidationError{Message: "Collection name must be 1-100 characters long"}
+	ErrInvalidCollectionDescription = &ValidationError{Message: "Collection description must be at most 500 characters long"}
+	ErrInvalidCollectionIcon        = &ValidationError{Message: "Collection icon must be at most 32 characters long"}
+	ErrResourceInCollection         = &ValidationError{Message: "Resource is already in this collection"}
+	ErrResourceNotInCollection      = &ValidationError{Message: "Resource is not in this collection"}
+	ErrInvalidCollectionOrder       = &ValidationError{Message: "Order must list every resource in the collection exactly once"}
+)
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeviceAuthorization is one run of the OAuth 2.0 device authorization grant (RFC 8628).
+// The device polls with the secret device code while the user approves the short user code
+// from a browser where they are already logged in. Only a hash of the device code is stored.
+type DeviceAuthorization struct {
+	gorm.Model
+	DeviceCodeHash string    `gorm:"not null;uniqueIndex;size:64"`
+	UserCode       string    `gorm:"not null;uniqueIndex;size:16"`
+	ClientID       string    `gorm:"not null;size:100"`
+	Interval       int       `gorm:"not null"` // minimum seconds between polls
+	ExpiresAt      time.Time `gorm:"not null;index"`
+	LastPolledAt   *time.Time
+	UserID         *uint // the user who approved the request
+	ApprovedAt     *time.Time
+	DeniedAt       *time.Time
+	ConsumedAt     *time.Time // set once tokens have been issued
+}
+
+// IsPending reports whether the request is still waiting for the user
+func (d *DeviceAuthorization) IsPending(now time.Time) bool {
+	return d.ApprovedAt == nil && d.DeniedAt == nil && now.Before(d.ExpiresAt)
+}
+
+var (
+	ErrInvalidUserCode = &ValidationError{Message: "Invalid or expired code"}
+)
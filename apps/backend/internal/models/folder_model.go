@@ -0,0 +1,81 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// MaxFolderDepth limits nesting so materialized paths stay short
+const MaxFolderDepth = 32
+
+// Folder is a node in a user's folder tree. Path is the materialized path of folder IDs from
+// the top of the tree down to and including this folder, e.g. "/3/8/21/", so a whole subtree
+// can be found with one range scan over the (deleted_at, path) index (see SubtreeBounds).
+//
+// Sibling names are unique per parent. NULL parent IDs never collide in a unique index, so the
+// top level gets its own index keyed on the user instead.
+type Folder struct {
+	gorm.Model
+	UserID   uint   `json:"user_id" gorm:"not null;index;uniqueIndex:idx_folder_top_level_name,where:parent_id IS NULL AND deleted_at IS NULL"`
+	ParentID *uint  `json:"parent_id" gorm:"index;uniqueIndex:idx_folder_sibling_name,where:parent_id IS NOT NULL AND deleted_at IS NULL"`
+	Name     string `json:"name" gorm:"not null;size:100;uniqueIndex:idx_folder_top_level_name;uniqueIndex:idx_folder_sibling_name"`
+	Path     string `json:"path" gorm:"not null;index;size:512"`
+}
+
+// FolderPath returns the path of the folder with the given ID inside parent, or at the top level when parent is nil
+func FolderPath(parent *Folder, id uint) string {
+	prefix := "/"
+	if parent != nil {
+		prefix = parent.Path
+	}
+	return prefix + strconv.FormatUint(uint64(id), 10) + "/"
+}
+
+// AncestorIDs returns the IDs in the path, from the top of the tree down to the folder itself
+func (f *Folder) AncestorIDs() []uint {
+	var ids []uint
+	for _, part := range strings.Split(strings.Trim(f.Path, "/"), "/") {
+		if id, err := strconv.ParseUint(part, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// Depth is the number of folders from the top of the tree down to this one
+func (f *Folder) Depth() int {
+	return strings.Count(f.Path, "/") - 1
+}
+
+// SubtreeBounds returns the half-open range [from, to) holding the paths of the folder and
+// everything below it. Every path ends in "/", so bumping that last byte gives the first path
+// past the subtree. Unlike LIKE, which SQLite treats case-insensitively, a plain range can be
+// answered from an index.
+func (f *Folder) SubtreeBounds() (from, to string) {
+	return f.Path, f.Path[:len(f.Path)-1] + string(f.Path[len(f.Path)-1]+1)
+}
+
+// IsAncestorOf reports whether other is this folder or lies somewhere below it
+func (f *Folder) IsAncestorOf(other *Folder) bool {
+	return strings.HasPrefix(other.Path, f.Path)
+}
+
+// ValidateFolderName trims a folder name and checks it can be used as a path segment
+func ValidateFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 || strings.Contains(name, "/") {
+		return "", ErrInvalidFolderName
+	}
+	return name, nil
+}
+
+var (
+	ErrFolderNotFound    = &ValidationError{Message: "Folder not found"}
+	ErrInvalidFolderName = &ValidationError{Message: "Folder name must be 1-100 characters long and cannot contain /"}
+	ErrFolderNameTaken   = &ValidationError{Message: "A folder with this name already exists here"}
+	ErrFolderCycle       = &ValidationError{Message: "A folder cannot be moved into itself or one of its subfolders"}
+	ErrFolderTooDeep     = &ValidationError{Message: "Folders cannot be nested that deeply"}
+)
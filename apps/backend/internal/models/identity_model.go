@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a DevLink user to an account at an external OpenID Connect provider.
+// A user can have several identities, but each provider subject maps to exactly one user.
+type UserIdentity struct {
+	gorm.Model
+	UserID   uint   `json:"user_id" gorm:"not null;index"`
+	Provider string `json:"provider" gorm:"not null;size:64;uniqueIndex:idx_identity_provider_subject"`
+	Subject  string `json:"subject" gorm:"not null;size:255;uniqueIndex:idx_identity_provider_subject"`
+	Email    string `json:"email"`
+}
+
+// OIDCLoginState holds what is needed to finish an authorization code flow between the
+// redirect to the provider and the callback. Each state is single-use.
+type OIDCLoginState struct {
+	gorm.Model
+	StateHash    string    `gorm:"not null;uniqueIndex;size:64"`
+	Provider     string    `gorm:"not null;size:64"`
+	Nonce        string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"not null"`
+	LinkUserID   *uint     // set when a logged-in user is linking a new identity
+	ExpiresAt    time.Time `gorm:"not null"`
+}
+
+var (
+	ErrUnknownProvider     = &ValidationError{Message: "Unknown identity provider"}
+	ErrInvalidOIDCState    = &ValidationError{Message: "Invalid or expired login attempt, please try again"}
+	ErrOIDCLoginFailed     = &ValidationError{Message: "Could not sign in with the identity provider"}
+	ErrOIDCEmailRequired   = &ValidationError{Message: "The identity provider did not share an email address"}
+	ErrOIDCEmailUnverified = &ValidationError{Message: "An account with this email already exists. Log in and link the provider from your account"}
+	ErrIdentityLinked      = &ValidationError{Message: "This identity is already linked to another account"}
+	ErrLastLoginMethod     = &ValidationError{Message: "Set a password or link another provider before removing your last sign-in method"}
+)
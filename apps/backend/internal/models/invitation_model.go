@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InvitationCodePrefix marks invitation codes so they are easy to recognise when pasted
+const InvitationCodePrefix = "dli_"
+
+// Invitation lets new users register while registration is invite-only. A code can be used
+// MaxUses times before it expires and, when Email is set, only by that address.
+// Only a hash of the code is stored; the plain value is shown once on creation.
+type Invitation struct {
+	gorm.Model
+	InviterID  uint       `json:"inviter_id" gorm:"not null;index"`
+	CodeHash   string     `json:"-" gorm:"not null;uniqueIndex;size:64"`
+	CodePrefix string     `json:"code_prefix" gorm:"not null;size:16"`
+	Email      string     `json:"email"`
+	MaxUses    int        `json:"max_uses" gorm:"not null;default:1"`
+	Uses       int        `json:"uses" gorm:"not null;default:0"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// IsUsable reports whether the invitation can still be redeemed
+func (i *Invitation) IsUsable(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt) && i.Uses < i.MaxUses
+}
+
+// AllowsEmail reports whether the given address may redeem the invitation
+func (i *Invitation) AllowsEmail(email string) bool {
+	return i.Email == "" || strings.EqualFold(i.Email, strings.TrimSpace(email))
+}
+
+var (
+	ErrInvitationRequired      = &ValidationError{Message: "Registration is by invitation only. Enter your invitation code"}
+	ErrInvalidInvitation       = &ValidationError{Message: "Invitation code is invalid, expired or already used"}
+	ErrInvitationEmailMismatch = &ValidationError{Message: "This invitation was issued for a different email address"}
+	ErrInvalidInvitationUses   = &ValidationError{Message: "Invitation uses must be between 1 and 100"}
+	ErrInvalidInvitationExpiry = &ValidationError{Message: "Invitation expiry must be between 1 and 90 days"}
+	ErrInvitationNotFound      = &ValidationError{Message: "Invitation not found"}
+)
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// LoginThrottle counts recent failed logins for one account or one client IP.
+// Key is "account:<email>" or "ip:<address>" so both are tracked in the same table.
+type LoginThrottle struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Key           string     `json:"key" gorm:"column:throttle_key;not null;uniqueIndex;size:320"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// IsLocked reports whether the key is in a lockout at the given time
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil != nil && t.LockedUntil.After(now)
+}
+
+var (
+	ErrLoginThrottled     = &ValidationError{Message: "Too many failed login attempts. Please try again later"}
+	ErrMagicLinkThrottled = &ValidationError{Message: "Too many sign-in links requested for this email. Please try again later"}
+)
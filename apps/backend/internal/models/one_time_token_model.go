@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenPurpose says which flow a one-time token belongs to so a token from one flow cannot be used in another
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposeAccountUnlock     TokenPurpose = "account_unlock"
+	TokenPurposeMagicLogin        TokenPurpose = "magic_login"
+)
+
+// OneTimeToken is a single-use, time-limited token delivered to the user out of band, e.g. by email.
+// Only a hash of the token is stored.
+type OneTimeToken struct {
+	gorm.Model
+	UserID    uint         `json:"user_id" gorm:"not null;index"`
+	Purpose   TokenPurpose `json:"purpose" gorm:"not null;type:varchar(32);index"`
+	TokenHash string       `json:"-" gorm:"not null;uniqueIndex;size:64"`
+	Email     string       `json:"email"` // address the token was sent to, when it matters to the flow
+	ExpiresAt time.Time    `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time   `json:"used_at"`
+}
+
+var (
+	ErrInvalidOneTimeToken = &ValidationError{Message: "This link is invalid or has expired"}
+)
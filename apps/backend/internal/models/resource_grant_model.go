@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// GrantPermission is what a grant lets another user do with a resource
+type GrantPermission string
+
+const (
+	GrantPermissionRead GrantPermission = "read"
+	GrantPermissionEdit GrantPermission = "edit"
+)
+
+// IsValid reports whether p is a known permission
+func (p GrantPermission) IsValid() bool {
+	return p == GrantPermissionRead || p == GrantPermissionEdit
+}
+
+// ResourceGrant shares one resource with one user. Grantees can read it, or also edit it with
+// the edit permission; deleting and resharing stay with the owner.
+type ResourceGrant struct {
+	ID          uint            `json:"id" gorm:"primarykey"`
+	ResourceID  uint            `json:"resource_id" gorm:"not null;uniqueIndex:idx_resource_grant,priority:1"`
+	UserID      uint            `json:"user_id" gorm:"not null;uniqueIndex:idx_resource_grant,priority:2;index:idx_resource_grant_user"`
+	Permission  GrantPermission `json:"permission" gorm:"not null;type:varchar(10)"`
+	GrantedByID uint            `json:"granted_by_id" gorm:"not null"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+var (
+	ErrInvalidGrantPermission = &ValidationError{Message: "Permission must be read or edit"}
+	ErrGrantNotFound          = &ValidationError{Message: "This resource is not shared with that user"}
+	ErrGrantToOwner           = &ValidationError{Message: "The owner already has full access to this resource"}
+)
@@ -7,6 +7,7 @@ import (
 
 type ResourceType string
 type LinkCategory string
+type ResourceVisibility string
 
 const (
 	ResourceTypeLink ResourceType = "link"
@@ -20,11 +21,20 @@ const (
 	LinkCategoryOther   LinkCategory = "other"
 )
 
+// Private resources are only visible to their owner. Unlisted ones can be read by anyone who
+// knows their ID, and public ones are also listed in the public feed.
+const (
+	VisibilityPrivate  ResourceVisibility = "private"
+	VisibilityUnlisted ResourceVisibility = "unlisted"
+	VisibilityPublic   ResourceVisibility = "public"
+)
+
 type Resource struct {
 	gorm.Model
 	Title       string         `json:"title" gorm:"not null"`
 	Type        ResourceType   `json:"type" gorm:"not null;type:varchar(10)"`
-	URL         string         `json:"url" gorm:"uniqueIndex"`
+	URL         string         `json:"url"`
+	RawURL      string         `json:"raw_url"` // URL exactly as it was sent, before normalization
 	Category    LinkCategory   `json:"category" gorm:"type:varchar(20)"`
 	Description string         `json:"description"`
 	Tags        datatypes.JSON `json:"tags"`
@@ -33,11 +43,41 @@ type Resource struct {
 	Language    string `json:"language"`
 	CodeContent string `json:"code_content" gorm:"type:text"`
 
-	UserID uint `json:"user_id" gorm:"not null"`
+	UserID uint `json:"user_id" gorm:"not null;index;uniqueIndex:idx_resource_personal_link,where:workspace_id IS NULL AND deleted_at IS NULL"`
+
+	// Workspace that owns the resource; nil means it is in UserID's personal library
+	WorkspaceID *uint `json:"workspace_id" gorm:"index;uniqueIndex:idx_resource_workspace_link,where:deleted_at IS NULL"`
+
+	// Normalized form of URL shared with other users' resources; nil for resources without a URL.
+	// A library holds at most one resource per link.
+	CanonicalLinkID *uint `json:"canonical_link_id" gorm:"index;uniqueIndex:idx_resource_personal_link;uniqueIndex:idx_resource_workspace_link"`
+
+	// Folder the resource is filed in; nil means the top level
+	FolderID *uint `json:"folder_id" gorm:"index"`
+
+	Visibility ResourceVisibility `json:"visibility" gorm:"not null;type:varchar(10);default:private;index"`
+}
+
+var (
+	ErrResourceNotFound  = &ValidationError{Message: "Resource not found"}
+	ErrInvalidVisibility = &ValidationError{Message: "Visibility must be private, unlisted or public"}
+)
+
+// IsPubliclyReadable reports whether anyone may read the resource without owning it
+func (r *Resource) IsPubliclyReadable() bool {
+	return r.Visibility == VisibilityUnlisted || r.Visibility == VisibilityPublic
 }
 
 // Validate checks if the resource is valid based on its type
 func (r *Resource) Validate() error {
+	switch r.Visibility {
+	case "":
+		r.Visibility = VisibilityPrivate
+	case VisibilityPrivate, VisibilityUnlisted, VisibilityPublic:
+	default:
+		return ErrInvalidVisibility
+	}
+
 	switch r.Type {
 	case ResourceTypeLink:
 		if r.URL == "" {
@@ -0,0 +1,31 @@
+package models
+
+// Role decides what a user may do beyond managing their own account and resources
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+// IsValid reports whether r is a known role
+func (r Role) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast reports whether r grants everything other grants. Roles are ordered user < moderator < admin.
+func (r Role) AtLeast(other Role) bool {
+	return roleRanks[r] >= roleRanks[other]
+}
+
+var (
+	ErrInvalidRole = &ValidationError{Message: "Role must be one of: user, moderator, admin"}
+)
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// SavedQuery is a named resource search that is re-run every time it is opened, so its
+// results always reflect the user's current resources
+type SavedQuery struct {
+	gorm.Model
+	UserID        uint           `json:"user_id" gorm:"not null;index"`
+	Name          string         `json:"name" gorm:"not null;size:100"`
+	Query         string         `json:"query" gorm:"size:200"`
+	Tags          datatypes.JSON `json:"tags"`
+	Type          ResourceType   `json:"type" gorm:"type:varchar(10)"`
+	Category      LinkCategory   `json:"category" gorm:"type:varchar(20)"`
+	Language      string         `json:"language" gorm:"size:20"`
+	CreatedAfter  *time.Time     `json:"created_after"`
+	CreatedBefore *time.Time     `json:"created_before"`
+	PinnedAt      *time.Time     `json:"pinned_at"`
+}
+
+// TagList returns the tags the query requires
+func (q *SavedQuery) TagList() []string {
+	var tags []string
+	if q.Tags != nil {
+		json.Unmarshal(q.Tags, &tags)
+	}
+	return tags
+}
+
+// Validate trims and checks the saved criteria
+func (q *SavedQuery) Validate() error {
+	q.Name = strings.TrimSpace(q.Name)
+	q.Query = strings.TrimSpace(q.Query)
+	q.Language = strings.TrimSpace(q.Language)
+	if q.Name == "" || utf8.RuneCountInString(q.Name) > 100 {
+		return ErrInvalidSavedQueryName
+	}
+	if utf8.RuneCountInString(q.Query) > 200 {
+		return ErrInvalidSavedQueryText
+	}
+	tags := q.TagList()
+	if len(tags) > 10 {
+		return ErrInvalidSavedQueryTags
+	}
+	for _, tag := range tags {
+		if tag == "" || utf8.RuneCountInString(tag) > 30 {
+			return ErrInvalidSavedQueryTags
+		}
+	}
+	switch q.Type {
+	case "", ResourceTypeLink, ResourceTypeCode:
+	default:
+		return &ValidationError{Message: "Invalid resource type"}
+	}
+	switch q.Category {
+	case "", LinkCategoryGitHub, LinkCategoryArticle, LinkCategoryTool, LinkCategoryOther:
+	default:
+		return &ValidationError{Message: "Invalid category"}
+	}
+	if utf8.RuneCountInString(q.Language) > 20 {
+		return ErrInvalidSavedQueryLanguage
+	}
+	if q.CreatedAfter != nil && q.CreatedBefore != nil && !q.CreatedAfter.Before(*q.CreatedBefore) {
+		return ErrInvalidSavedQueryDates
+	}
+	return nil
+}
+
+var (
+	ErrSavedQueryNotFound        = &ValidationError{Message: "Saved query not found"}
+	ErrInvalidSavedQueryName     = &ValidationError{Message: "Saved query name must be 1-100 characters long"}
+	ErrInvalidSavedQueryText     = &ValidationError{Message: "Search text must be at most 200 characters long"}
+	ErrInvalidSavedQueryTags     = &ValidationError{Message: "A saved query can have up to 10 tags of 1-30 characters"}
+	ErrInvalidSavedQueryLanguage = &ValidationError{Message: "Language must be at most 20 characters long"}
+	ErrInvalidSavedQueryDates    = &ValidationError{Message: "created_after must be before created_before"}
+)
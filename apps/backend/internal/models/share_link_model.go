@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShareLinkTokenPrefix marks share tokens so they are easy to recognise when pasted
+const ShareLinkTokenPrefix = "dls_"
+
+// ShareLink gives anyone holding its token read access to one resource, whatever the resource's
+// visibility. Links can expire and can be revoked by the owner at any time.
+// Only a hash of the token is stored; the plain value is shown once on creation.
+type ShareLink struct {
+	gorm.Model
+	ResourceID     uint       `json:"resource_id" gorm:"not null;index"`
+	CreatedByID    uint       `json:"created_by_id" gorm:"not null"`
+	TokenHash      string     `json:"-" gorm:"not null;uniqueIndex;size:64"`
+	TokenPrefix    string     `json:"token_prefix" gorm:"not null;size:16"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	Views          int        `json:"views" gorm:"not null;default:0"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+}
+
+// IsActive reports whether the link can still be used; links without an expiry never expire
+func (l *ShareLink) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && (l.ExpiresAt == nil || now.Before(*l.ExpiresAt))
+}
+
+var (
+	ErrShareLinkNotFound      = &ValidationError{Message: "Share link not found"}
+	ErrInvalidShareLink       = &ValidationError{Message: "Share link is invalid, expired or revoked"}
+	ErrInvalidShareLinkExpiry = &ValidationError{Message: "Share link expiry must be between 1 and 8760 hours"}
+)
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken is a single-use token that can be exchanged for a new access token.
+// Tokens issued from the same login share a FamilyID so a whole chain can be revoked at once.
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	FamilyID  string     `json:"family_id" gorm:"not null;index;size:64"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex;size:64"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	RotatedAt *time.Time `json:"rotated_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// IsActive reports whether the token can still be exchanged
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RotatedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// RevokedToken records the jti of an access token that must no longer be accepted.
+// Entries are only needed until the token would have expired anyway.
+type RevokedToken struct {
+	ID        uint      `gorm:"primarykey"`
+	JTI       string    `gorm:"not null;uniqueIndex;size:64"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+var (
+	ErrInvalidRefreshToken = &ValidationError{Message: "Invalid or expired refresh token"}
+	ErrTokenRevoked        = &ValidationError{Message: "Token has been revoked"}
+	ErrInvalidCSRFToken    = &ValidationError{Message: "Missing or invalid CSRF token"}
+)
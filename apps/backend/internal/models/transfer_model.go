@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type TransferStatus string
+
+const (
+	TransferStatusPending   TransferStatus = "pending"
+	TransferStatusAccepted  TransferStatus = "accepted"
+	TransferStatusDeclined  TransferStatus = "declined"
+	TransferStatusCancelled TransferStatus = "cancelled"
+)
+
+// TransferConflictPolicy decides what accepting a transfer does with resources whose URL the
+// recipient has already saved
+type TransferConflictPolicy string
+
+const (
+	// TransferConflictFail aborts the whole transfer and reports the conflicts
+	TransferConflictFail TransferConflictPolicy = "fail"
+	// TransferConflictSkip moves everything else and leaves the duplicates with the sender
+	TransferConflictSkip TransferConflictPolicy = "skip"
+)
+
+// MaxTransferResources caps how many resources can be picked for one transfer
+const MaxTransferResources = 500
+
+// ResourceTransfer offers personal resources to another user, who has to accept before
+// ownership changes. WholeAccount transfers cover every personal resource the sender has
+// when the transfer is accepted; otherwise ResourceIDs lists the selection.
+type ResourceTransfer struct {
+	gorm.Model
+	FromUserID   uint           `json:"from_user_id" gorm:"not null;index"`
+	ToUserID     uint           `json:"to_user_id" gorm:"not null;index"`
+	WholeAccount bool           `json:"whole_account" gorm:"not null;default:false"`
+	ResourceIDs  datatypes.JSON `json:"resource_ids"`
+	Message      string         `json:"message" gorm:"size:500"`
+	Status       TransferStatus `json:"status" gorm:"not null;type:varchar(10);default:pending;index"`
+	ExpiresAt    time.Time      `json:"expires_at" gorm:"not null"`
+	RespondedAt  *time.Time     `json:"responded_at"`
+	MovedCount   int            `json:"moved_count" gorm:"not null;default:0"`
+	SkippedCount int            `json:"skipped_count" gorm:"not null;default:0"`
+}
+
+// ResourceIDList returns the selected resource IDs
+func (t *ResourceTransfer) ResourceIDList() []uint {
+	var ids []uint
+	if t.ResourceIDs != nil {
+		json.Unmarshal(t.ResourceIDs, &ids)
+	}
+	return ids
+}
+
+// IsPending reports whether the transfer can still be answered
+func (t *ResourceTransfer) IsPending(now time.Time) bool {
+	return t.Status == TransferStatusPending && now.Before(t.ExpiresAt)
+}
+
+// TransferConflict is a resource the recipient already has under the same URL
+type TransferConflict struct {
+	ResourceID         uint   `json:"resource_id"`
+	ExistingResourceID uint   `json:"existing_resource_id"`
+	URL                string `json:"url"`
+}
+
+var (
+	ErrTransferNotFound         = &ValidationError{Message: "Transfer not found"}
+	ErrTransferNotPending       = &ValidationError{Message: "This transfer has already been answered or has expired"}
+	ErrTransferToSelf           = &ValidationError{Message: "You cannot transfer resources to yourself"}
+	ErrInvalidTransferSelection = &ValidationError{Message: "Pick 1-500 of your personal resources, or the whole account"}
+	ErrTransferResourceNotOwned = &ValidationError{Message: "Only personal resources you own can be transferred"}
+	ErrTransferConflicts        = &ValidationError{Message: "You already have some of these URLs saved. Accept with on_conflict=skip to leave them with the sender"}
+	ErrInvalidTransferConflict  = &ValidationError{Message: "on_conflict must be fail or skip"}
+)
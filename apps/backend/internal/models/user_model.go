@@ -2,6 +2,7 @@ package models
 
 import (
 	"regexp"
+	"time"
 	"unicode"
 
 	"gorm.io/gorm"
@@ -13,6 +14,59 @@ type User struct {
 	Email     string     `json:"email" gorm:"not null;uniqueIndex" validate:"required,email"`
 	Password  string     `json:"password" gorm:"not null" validate:"required,min=8"`
 	Resources []Resource `json:"resources"`
+
+	// Email verification; a changed address stays pending until confirmed
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PendingEmail    string     `json:"pending_email"`
+
+	// TOTP two-factor authentication; the secret is set on enrollment and enabled once confirmed
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabledAt *time.Time `json:"totp_enabled_at"`
+	TOTPLastStep  int64      `json:"-"`
+
+	// Authorization and moderation
+	Role                  Role       `json:"role" gorm:"not null;default:user;size:16"`
+	SuspendedAt           *time.Time `json:"suspended_at"`
+	SuspendedReason       string     `json:"suspended_reason"`
+	PasswordResetRequired bool       `json:"password_reset_required" gorm:"not null;default:false"`
+
+	// Who invited the user, when they registered with an invitation
+	InvitedByID  *uint `json:"invited_by_id" gorm:"index"`
+	InvitationID *uint `json:"invitation_id" gorm:"index"`
+}
+
+// CurrentRole returns the user's role, treating rows created before roles existed as plain users
+func (u *User) CurrentRole() Role {
+	if u.Role == "" {
+		return RoleUser
+	}
+	return u.Role
+}
+
+// HasRole reports whether the user's role grants at least the given role
+func (u *User) HasRole(role Role) bool {
+	return u.CurrentRole().AtLeast(role)
+}
+
+// IsSuspended reports whether a moderator has blocked the account
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+// HasPassword reports whether the user can log in with a password. Accounts created
+// through an identity provider start without one.
+func (u *User) HasPassword() bool {
+	return u.Password != ""
+}
+
+// IsMFAEnabled reports whether login requires a second factor
+func (u *User) IsMFAEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// IsEmailVerified reports whether the current email address has been confirmed
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // ValidatePassword checks if the password meets the requirements
@@ -65,13 +119,27 @@ func (u *User) ValidateUsername() error {
 
 // Custom errors
 var (
-	ErrInvalidPassword    = &ValidationError{Message: "Password must be at least 8 characters long and contain uppercase, lowercase, number, and special character"}
-	ErrInvalidEmail       = &ValidationError{Message: "Invalid email format"}
-	ErrInvalidUsername    = &ValidationError{Message: "Username must be 3-50 characters long and contain only letters, numbers, and underscores"}
-	ErrEmailExists        = &ValidationError{Message: "Email already registered"}
-	ErrInvalidCredentials = &ValidationError{Message: "Invalid email or password"}
-	ErrForbidden          = &ValidationError{Message: "You don't have permission to perform this action"}
-	ErrInvalidRequest     = &ValidationError{Message: "Invalid request"}
+	ErrInvalidPassword         = &ValidationError{Message: "Password must be at least 8 characters long and contain uppercase, lowercase, number, and special character"}
+	ErrInvalidEmail            = &ValidationError{Message: "Invalid email format"}
+	ErrInvalidUsername         = &ValidationError{Message: "Username must be 3-50 characters long and contain only letters, numbers, and underscores"}
+	ErrEmailExists             = &ValidationError{Message: "Email already registered"}
+	ErrInvalidCredentials      = &ValidationError{Message: "Invalid email or password"}
+	ErrForbidden               = &ValidationError{Message: "You don't have permission to perform this action"}
+	ErrInvalidRequest          = &ValidationError{Message: "Invalid request"}
+	ErrEmailNotVerified        = &ValidationError{Message: "Please verify your email address first"}
+	ErrEmailVerified           = &ValidationError{Message: "Email address is already verified"}
+	ErrMFAAlreadyEnabled       = &ValidationError{Message: "Two-factor authentication is already enabled"}
+	ErrMFANotEnabled           = &ValidationError{Message: "Two-factor authentication is not enabled"}
+	ErrMFANotEnrolled          = &ValidationError{Message: "Start two-factor enrollment first"}
+	ErrInvalidMFACode          = &ValidationError{Message: "Invalid authentication code"}
+	ErrInvalidMFAToken         = &ValidationError{Message: "Invalid or expired two-factor challenge"}
+	ErrAccountSuspended        = &ValidationError{Message: "This account has been suspended"}
+	ErrPasswordResetRequired   = &ValidationError{Message: "A password reset is required for this account. Check your email for a reset link"}
+	ErrCurrentPasswordRequired = &ValidationError{Message: "Enter your current password to set a new one"}
+	ErrInvalidCurrentPassword  = &ValidationError{Message: "Current password is incorrect"}
+	ErrLastAdmin               = &ValidationError{Message: "The last admin cannot be demoted, suspended or deleted"}
+	ErrInvalidPoWSolution      = &ValidationError{Message: "Registration challenge is missing, expired or not solved. Request a new one"}
+	ErrUserNotFound            = &ValidationError{Message: "User not found"}
 )
 
 type ValidationError struct {
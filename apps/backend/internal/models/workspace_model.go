@@ -0,0 +1,88 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// WorkspaceRole decides what a member may do in a workspace
+type WorkspaceRole string
+
+const (
+	WorkspaceRoleViewer WorkspaceRole = "viewer"
+	WorkspaceRoleEditor WorkspaceRole = "editor"
+	WorkspaceRoleOwner  WorkspaceRole = "owner"
+)
+
+var workspaceRoleRanks = map[WorkspaceRole]int{
+	WorkspaceRoleViewer: 1,
+	WorkspaceRoleEditor: 2,
+	WorkspaceRoleOwner:  3,
+}
+
+// IsValid reports whether r is a known workspace role
+func (r WorkspaceRole) IsValid() bool {
+	_, ok := workspaceRoleRanks[r]
+	return ok
+}
+
+// AtLeast reports whether r grants everything other grants. Roles are ordered viewer < editor < owner.
+func (r WorkspaceRole) AtLeast(other WorkspaceRole) bool {
+	return workspaceRoleRanks[r] >= workspaceRoleRanks[other]
+}
+
+// Workspace is a shared library. Resources and collections can belong to a workspace instead
+// of a single user; UserID on them then records who created them.
+type Workspace struct {
+	gorm.Model
+	Name        string `json:"name" gorm:"not null;size:100"`
+	Description string `json:"description" gorm:"size:500"`
+	CreatedByID uint   `json:"created_by_id" gorm:"not null"`
+}
+
+// WorkspaceMember gives a user a role in a workspace. Invited members have no AcceptedAt and
+// get no access until they accept.
+type WorkspaceMember struct {
+	ID          uint          `json:"id" gorm:"primarykey"`
+	WorkspaceID uint          `json:"workspace_id" gorm:"not null;uniqueIndex:idx_workspace_member"`
+	UserID      uint          `json:"user_id" gorm:"not null;uniqueIndex:idx_workspace_member;index"`
+	Role        WorkspaceRole `json:"role" gorm:"not null;type:varchar(10)"`
+	InvitedByID *uint         `json:"invited_by_id"`
+	AcceptedAt  *time.Time    `json:"accepted_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// IsActive reports whether the member has accepted and holds their role
+func (m *WorkspaceMember) IsActive() bool {
+	return m.AcceptedAt != nil
+}
+
+// Validate trims and checks the user-editable fields
+func (w *Workspace) Validate() error {
+	w.Name = strings.TrimSpace(w.Name)
+	w.Description = strings.TrimSpace(w.Description)
+	if w.Name == "" || utf8.RuneCountInString(w.Name) > 100 {
+		return ErrInvalidWorkspaceName
+	}
+	if utf8.RuneCountInString(w.Description) > 500 {
+		return ErrInvalidWorkspaceDescription
+	}
+	return nil
+}
+
+var (
+	ErrWorkspaceNotFound           = &ValidationError{Message: "Workspace not found"}
+	ErrInvalidWorkspaceName        = &ValidationError{Message: "Workspace name must be 1-100 characters long"}
+	ErrInvalidWorkspaceDescription = &ValidationError{Message: "Workspace description must be at most 500 characters long"}
+	ErrInvalidWorkspaceRole        = &ValidationError{Message: "Role must be one of: owner, editor, viewer"}
+	ErrWorkspaceMemberExists       = &ValidationError{Message: "This user is already a member or has a pending invitation"}
+	ErrWorkspaceMemberNotFound     = &ValidationError{Message: "Workspace member not found"}
+	ErrWorkspaceInvitationNotFound = &ValidationError{Message: "Workspace invitation not found"}
+	ErrLastWorkspaceOwner          = &ValidationError{Message: "A workspace needs at least one owner. Make someone else an owner first"}
+	ErrWorkspaceFolder             = &ValidationError{Message: "Folders are personal; workspace resources cannot be filed in them"}
+	ErrSoleWorkspaceOwner          = &ValidationError{Message: "This user is the only owner of a workspace with other members. Make someone else an owner first"}
+)
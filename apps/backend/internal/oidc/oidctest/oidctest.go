@@ -0,0 +1,203 @@
+// Package oidctest runs a minimal OpenID Connect provider for tests: discovery, a token endpoint
+// that checks PKCE and client credentials, and a JWKS endpoint whose keys can be rotated.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ClientID     = "devlink-test"
+	ClientSecret = "s3cret"
+)
+
+// Server is a mock identity provider
+type Server struct {
+	URL string
+
+	t      *testing.T
+	server *httptest.Server
+
+	mu         sync.Mutex
+	keys       map[string]*rsa.PrivateKey
+	signingKid string
+	challenges map[string]string // code -> PKCE challenge
+	idTokens   map[string]string // code -> ID token handed out for it
+	codes      int
+	jwksHits   int
+}
+
+// NewServer starts a provider with no keys; it is shut down when the test ends
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+	s := &Server{
+		t:          t,
+		keys:       map[string]*rsa.PrivateKey{},
+		challenges: map[string]string{},
+		idTokens:   map[string]string{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/authorize",
+			"token_endpoint":         s.URL + "/token",
+			"jwks_uri":               s.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/token", s.handleToken)
+	mux.HandleFunc("/jwks", s.handleJWKS)
+	s.server = httptest.NewServer(mux)
+	s.URL = s.server.URL
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != ClientID || pass != ClientSecret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+		return
+	}
+	code := r.PostForm.Get("code")
+	challenge, known := s.challenges[code]
+	if !known || codeChallenge(r.PostForm.Get("code_verifier")) != challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	delete(s.challenges, code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "at",
+		"token_type":   "Bearer",
+		"id_token":     s.idTokens[code],
+		"expires_in":   3600,
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jwksHits++
+
+	keys := make([]map[string]string, 0, len(s.keys))
+	for kid, key := range s.keys {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+}
+
+// AddKey generates a signing key and publishes it in the JWKS under kid. The newest key signs
+// the ID tokens Authorize hands out.
+func (s *Server) AddKey(kid string) *rsa.PrivateKey {
+	s.t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	s.mu.Lock()
+	s.keys[kid] = key
+	s.signingKid = kid
+	s.mu.Unlock()
+	return key
+}
+
+// Issue records what the authorization endpoint would have issued for a code
+func (s *Server) Issue(code, challenge, idToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.challenges[code] = challenge
+	s.idTokens[code] = idToken
+}
+
+// Authorize plays the user approving the authorization request in authURL. It returns the code
+// the provider would redirect back with and the state to send along; the ID token for the code
+// carries the request's nonce plus claims.
+func (s *Server) Authorize(authURL string, claims jwt.MapClaims) (code, state string) {
+	s.t.Helper()
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("client_id") != ClientID || query.Get("code_challenge_method") != "S256" {
+		s.t.Fatalf("unexpected authorization request %s", authURL)
+	}
+
+	s.mu.Lock()
+	s.codes++
+	code = "code-" + strconv.Itoa(s.codes)
+	kid := s.signingKid
+	key := s.keys[kid]
+	s.mu.Unlock()
+
+	idClaims := s.Claims()
+	idClaims["nonce"] = query.Get("nonce")
+	for name, value := range claims {
+		idClaims[name] = value
+	}
+	s.Issue(code, query.Get("code_challenge"), s.Sign(key, kid, idClaims))
+	return code, query.Get("state")
+}
+
+// Claims returns valid ID token claims for the test client; callers add nonce, email and so on
+func (s *Server) Claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss": s.URL,
+		"sub": "user-1",
+		"aud": ClientID,
+		"exp": now.Add(time.Hour).Unix(),
+		"iat": now.Unix(),
+	}
+}
+
+// Sign signs an ID token with key under kid
+func (s *Server) Sign(key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	s.t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return signed
+}
+
+// JWKSHits reports how many times the key set was fetched
+func (s *Server) JWKSHits() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jwksHits
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,152 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"devlink/internal/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testNonce = "nonce-123"
+
+func newTestProvider(idp *oidctest.Server) *Provider {
+	return NewProvider(Config{
+		Name:         "mock",
+		Issuer:       idp.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  "http://localhost/callback",
+	}, nil)
+}
+
+func testClaims(idp *oidctest.Server) jwt.MapClaims {
+	claims := idp.Claims()
+	claims["nonce"] = testNonce
+	claims["email"] = "user@example.com"
+	return claims
+}
+
+func TestProviderCodeExchange(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	key := idp.AddKey("k1")
+	p := newTestProvider(idp)
+	ctx := context.Background()
+
+	verifier, err := GenerateCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := p.AuthCodeURL(ctx, "state-1", testNonce, CodeChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if parsed.Path != "/authorize" || query.Get("code_challenge_method") != "S256" || query.Get("nonce") != testNonce {
+		t.Fatalf("unexpected authorization URL %s", authURL)
+	}
+
+	idp.Issue("code-1", query.Get("code_challenge"), idp.Sign(key, "k1", testClaims(idp)))
+
+	if _, err := p.Exchange(ctx, "code-1", "not-the-verifier"); !errors.Is(err, ErrTokenExchange) {
+		t.Fatalf("exchange with the wrong verifier: got %v, want ErrTokenExchange", err)
+	}
+	tokens, err := p.Exchange(ctx, "code-1", verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	claims, err := p.VerifyIDToken(ctx, tokens.IDToken, testNonce)
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "user@example.com" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
+
+func TestProviderVerifyIDToken(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	key := idp.AddKey("k1")
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     *rsa.PrivateKey
+		nonce   string
+		modify  func(jwt.MapClaims)
+		wantErr bool
+	}{
+		{name: "valid", key: key, nonce: testNonce},
+		{name: "bad signature", key: otherKey, nonce: testNonce, wantErr: true},
+		{name: "wrong nonce", key: key, nonce: "another-nonce", wantErr: true},
+		{name: "wrong audience", key: key, nonce: testNonce, modify: func(c jwt.MapClaims) { c["aud"] = "someone-else" }, wantErr: true},
+		{name: "wrong issuer", key: key, nonce: testNonce, modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }, wantErr: true},
+		{name: "expired", key: key, nonce: testNonce, modify: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: true},
+	}
+
+	p := newTestProvider(idp)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := testClaims(idp)
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			_, err := p.VerifyIDToken(context.Background(), idp.Sign(tt.key, "k1", claims), tt.nonce)
+			if tt.wantErr && !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("got %v, want ErrInvalidIDToken", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProviderRefetchesJWKSForUnknownKid(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	oldKey := idp.AddKey("old")
+	p := newTestProvider(idp)
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, idp.Sign(oldKey, "old", testClaims(idp)), testNonce); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if hits := idp.JWKSHits(); hits != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", hits)
+	}
+
+	// The provider rotates its key. Within the refresh interval the new kid is not looked up yet,
+	// so a stream of tokens with made-up kids cannot hammer the provider.
+	newKey := idp.AddKey("new")
+	rotated := idp.Sign(newKey, "new", testClaims(idp))
+	if _, err := p.VerifyIDToken(ctx, rotated, testNonce); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("got %v, want ErrInvalidIDToken within the refresh interval", err)
+	}
+	if hits := idp.JWKSHits(); hits != 1 {
+		t.Fatalf("JWKS fetched %d times within the refresh interval, want 1", hits)
+	}
+
+	p.mu.Lock()
+	p.keysFetchedAt = time.Now().Add(-jwksRefreshInterval)
+	p.mu.Unlock()
+
+	if _, err := p.VerifyIDToken(ctx, rotated, testNonce); err != nil {
+		t.Fatalf("VerifyIDToken after rotation: %v", err)
+	}
+	if hits := idp.JWKSHits(); hits != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", hits)
+	}
+}
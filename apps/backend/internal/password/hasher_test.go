@@ -0,0 +1,136 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testParams keep argon2id cheap; the format is what is under test, not the cost
+var testParams = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func newArgon2Hasher() *Hasher {
+	return &Hasher{Algorithm: AlgorithmArgon2id, Argon2: testParams, BcryptCost: bcrypt.MinCost}
+}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	h := newArgon2Hasher()
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected hash format %q", hash)
+	}
+
+	if ok, err := h.Verify("correct horse", hash); err != nil || !ok {
+		t.Fatalf("Verify(right password) = %v, %v", ok, err)
+	}
+	if ok, err := h.Verify("battery staple", hash); err != nil || ok {
+		t.Fatalf("Verify(wrong password) = %v, %v", ok, err)
+	}
+
+	// Salts are random, so hashing twice gives different strings that both verify
+	again, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == hash {
+		t.Fatal("two hashes of the same password are identical")
+	}
+}
+
+func TestVerifyLegacyBcrypt(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An argon2id hasher still accepts hashes from before the switch
+	h := newArgon2Hasher()
+	if ok, err := h.Verify("correct horse", string(legacy)); err != nil || !ok {
+		t.Fatalf("Verify(right password) = %v, %v", ok, err)
+	}
+	if ok, err := h.Verify("battery staple", string(legacy)); err != nil || ok {
+		t.Fatalf("Verify(wrong password) = %v, %v", ok, err)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	argon2Hasher := newArgon2Hasher()
+	current, err := argon2Hasher.Hash("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	weaker := *argon2Hasher
+	weaker.Argon2.Iterations = 2
+	stronger, err := weaker.Hash("pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	costlierBcrypt, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bcryptHasher := &Hasher{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost}
+
+	tests := []struct {
+		name   string
+		hasher *Hasher
+		hash   string
+		want   bool
+	}{
+		{name: "argon2id with current parameters", hasher: argon2Hasher, hash: current, want: false},
+		{name: "argon2id with other parameters", hasher: argon2Hasher, hash: stronger, want: true},
+		{name: "bcrypt under argon2id", hasher: argon2Hasher, hash: string(bcryptHash), want: true},
+		{name: "malformed under argon2id", hasher: argon2Hasher, hash: "$argon2id$v=19$m=64", want: true},
+		{name: "bcrypt with current cost", hasher: bcryptHasher, hash: string(bcryptHash), want: false},
+		{name: "bcrypt with other cost", hasher: bcryptHasher, hash: string(costlierBcrypt), want: true},
+		{name: "argon2id under bcrypt", hasher: bcryptHasher, hash: current, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.NeedsRehash(tt.hash); got != tt.want {
+				t.Fatalf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsMalformedHashes(t *testing.T) {
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "empty", hash: ""},
+		{name: "plain text", hash: "hunter2"},
+		{name: "other algorithm", hash: "$argon2i$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5"},
+		{name: "missing key", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ"},
+		{name: "extra section", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5$x"},
+		{name: "wrong version", hash: "$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHQ$a2V5a2V5"},
+		{name: "missing parameters", hash: "$argon2id$v=19$m=64$c2FsdHNhbHQ$a2V5a2V5"},
+		{name: "zero iterations", hash: "$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHQ$a2V5a2V5"},
+		{name: "zero parallelism", hash: "$argon2id$v=19$m=64,t=1,p=0$c2FsdHNhbHQ$a2V5a2V5"},
+		{name: "parallelism out of range", hash: "$argon2id$v=19$m=64,t=1,p=256$c2FsdHNhbHQ$a2V5a2V5"},
+		{name: "salt not base64", hash: "$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5a2V5"},
+		{name: "padded base64", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ=$a2V5a2V5"},
+		{name: "empty key", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHQ$"},
+	}
+
+	h := newArgon2Hasher()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := h.Verify("pw", tt.hash)
+			if ok || !errors.Is(err, ErrUnknownHashFormat) {
+				t.Fatalf("Verify = %v, %v; want false, ErrUnknownHashFormat", ok, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package policy decides who may do what with resources and collections. Handlers ask it
+// instead of comparing owner IDs themselves, so personal, workspace and public access follow
+// one set of rules.
+package policy
+
+import (
+	"errors"
+
+	"devlink/internal/models"
+	"devlink/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// Action is something a user wants to do with a resource or collection
+type Action string
+
+const (
+	ActionRead   Action = "read"
+	ActionEdit   Action = "edit"
+	ActionDelete Action = "delete"
+	// ActionShare covers changing visibility and managing share links
+	ActionShare Action = "share"
+	// ActionOrganize covers filing a resource into folders, which belong to one user
+	ActionOrganize Action = "organize"
+)
+
+// workspaceRoleFor is the lowest workspace role allowed to perform each action
+var workspaceRoleFor = map[Action]models.WorkspaceRole{
+	ActionRead:     models.WorkspaceRoleViewer,
+	ActionEdit:     models.WorkspaceRoleEditor,
+	ActionDelete:   models.WorkspaceRoleEditor,
+	ActionShare:    models.WorkspaceRoleOwner,
+	ActionOrganize: models.WorkspaceRoleEditor,
+}
+
+// grantPermissionFor is the grant permission needed for each action. Deleting, resharing and
+// organizing cannot be granted.
+var grantPermissionFor = map[Action][]models.GrantPermission{
+	ActionRead: {models.GrantPermissionRead, models.GrantPermissionEdit},
+	ActionEdit: {models.GrantPermissionEdit},
+}
+
+type Policy struct {
+	workspaces *repository.WorkspaceRepository
+	grants     *repository.ResourceGrantRepository
+}
+
+func NewPolicy(workspaceRepository *repository.WorkspaceRepository, grantRepository *repository.ResourceGrantRepository) *Policy {
+	return &Policy{
+		workspaces: workspaceRepository,
+		grants:     grantRepository,
+	}
+}
+
+// WorkspaceRole returns the user's role in the workspace, or "" unless they are an active member
+func (p *Policy) WorkspaceRole(workspaceID, userID uint) (models.WorkspaceRole, error) {
+	member, err := p.workspaces.GetMember(workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	if !member.IsActive() {
+		return "", nil
+	}
+	return member.Role, nil
+}
+
+// CanUseWorkspace reports whether the user holds at least role in the workspace
+func (p *Policy) CanUseWorkspace(userID, workspaceID uint, role models.WorkspaceRole) (bool, error) {
+	current, err := p.WorkspaceRole(workspaceID, userID)
+	if err != nil || current == "" {
+		return false, err
+	}
+	return current.AtLeast(role), nil
+}
+
+// CanAccessResource reports whether the user may perform action on the resource. Personal
+// resources are fully controlled by their owner, workspace resources by member role. Grants
+// let specific users read or edit, and anyone may read unlisted and public resources.
+func (p *Policy) CanAccessResource(userID uint, resource *models.Resource, action Action) (bool, error) {
+	if action == ActionRead && resource.IsPubliclyReadable() {
+		return true, nil
+	}
+	allowed, err := p.canAccessLibrary(userID, resource.UserID, resource.WorkspaceID, action)
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	permissions, ok := grantPermissionFor[action]
+	if !ok {
+		return false, nil
+	}
+	grant, err := p.grants.Get(resource.ID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, permission := range permissions {
+		if grant.Permission == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CanAccessCollection reports whether the user may perform action on the collection
+func (p *Policy) CanAccessCollection(userID uint, collection *models.Collection, action Action) (bool, error) {
+	return p.canAccessLibrary(userID, collection.UserID, collection.WorkspaceID, action)
+}
+
+func (p *Policy) canAccessLibrary(userID, ownerID uint, workspaceID *uint, action Action) (bool, error) {
+	if workspaceID == nil {
+		return ownerID == userID, nil
+	}
+	role, ok := workspaceRoleFor[action]
+	if !ok {
+		return false, nil
+	}
+	return p.CanUseWorkspace(userID, *workspaceID, role)
+}
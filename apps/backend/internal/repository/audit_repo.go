@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Record(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// GetByUserID returns the newest entries about a user first
+func (r *AuditRepository) GetByUserID(userID uint, offset, limit int) ([]models.AuditLog, int64, error) {
+	var entries []models.AuditLog
+	var total int64
+	query := r.db.Model(&models.AuditLog{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&entries).Error
+	return entries, total, err
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"errors"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CollectionRepository struct {
+	db *gorm.DB
+}
+
+func NewCollectionRepository(db *gorm.DB) *CollectionRepository {
+	return &CollectionRepository{db: db}
+}
+
+func (r *CollectionRepository) GetByID(collectionID uint) (*models.Collection, error) {
+	var collection models.Collection
+	if err := r.db.First(&collection, collectionID).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// GetByUserID returns a page of the user's personal collections, most recently created first
+func (r *CollectionRepository) GetByUserID(userID uint, page, pageSize int) ([]models.Collection, int64, error) {
+	return r.page(r.db.Model(&models.Collection{}).Where("user_id = ? AND workspace_id IS NULL", userID), page, pageSize)
+}
+
+// GetByWorkspaceID returns a page of the workspace's collections, most recently created first
+func (r *CollectionRepository) GetByWorkspaceID(workspaceID uint, page, pageSize int) ([]models.Collection, int64, error) {
+	return r.page(r.db.Model(&models.Collection{}).Where("workspace_id = ?", workspaceID), page, pageSize)
+}
+
+func (r *CollectionRepository) page(query *gorm.DB, page, pageSize int) ([]models.Collection, int64, error) {
+	var collections []models.Collection
+	var total int64
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&collections).Error; err != nil {
+		return nil, 0, err
+	}
+	return collections, total, nil
+}
+
+// CountResources returns how many resources each of the given collections holds
+func (r *CollectionRepository) CountResources(collectionIDs []uint) (map[uint]int, error) {
+	counts := make(map[uint]int, len(collectionIDs))
+	if len(collectionIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		CollectionID uint
+		Count        int
+	}
+	err := r.db.Model(&models.CollectionItem{}).
+		Select("collection_id, COUNT(*) AS count").
+		Where("collection_id IN ?", collectionIDs).
+		Group("collection_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.CollectionID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *CollectionRepository) CreateCollection(collection *models.Collection) error {
+	return r.db.Create(collection).Error
+}
+
+func (r *CollectionRepository) UpdateCollection(collection *models.Collection) error {
+	return r.db.Save(collection).Error
+}
+
+// DeleteCollection removes the collection and its memberships; the resources themselves stay
+func (r *CollectionRepository) DeleteCollection(collectionID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collectionID).Delete(&models.CollectionItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Collection{}, collectionID).Error
+	})
+}
+
+// GetResources returns a page of the collection's resources in their manual order
+func (r *CollectionRepository) GetResources(collectionID uint, page, pageSize int) ([]models.Resource, int64, error) {
+	var resources []models.Resource
+	var total int64
+
+	query := r.db.Model(&models.Resource{}).
+		Joins("JOIN collection_items ON collection_items.resource_id = resources.id").
+		Where("collection_items.collection_id = ?", collectionID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	err := query.Order("collection_items.position, collection_items.id").
+		Offset(offset).Limit(pageSize).
+		Find(&resources).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return resources, total, nil
+}
+
+// AddResource inserts a resource at position, or at the end when position is nil or past it
+func (r *CollectionRepository) AddResource(collectionID, resourceID uint, position *int) (*models.CollectionItem, error) {
+	item := &models.CollectionItem{CollectionID: collectionID, ResourceID: resourceID}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.CollectionItem{}).Where("collection_id = ?", collectionID).Count(&count).Error; err != nil {
+			return err
+		}
+		item.Position = int(count)
+		if position != nil && *position >= 0 && *position < item.Position {
+			item.Position = *position
+			// Make room by moving everything from the target position one step down
+			if err := tx.Model(&models.CollectionItem{}).
+				Where("collection_id = ? AND position >= ?", collectionID, item.Position).
+				Update("position", gorm.Expr("position + 1")).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(item)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrResourceInCollection
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// RemoveResource takes a resource out of the collection and closes the gap it leaves
+func (r *CollectionRepository) RemoveResource(collectionID, resourceID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var item models.CollectionItem
+		if err := tx.Where("collection_id = ? AND resource_id = ?", collectionID, resourceID).First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.ErrResourceNotInCollection
+			}
+			return err
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.CollectionItem{}).
+			Where("collection_id = ? AND position > ?", collectionID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error
+	})
+}
+
+// Reorder sets the collection's order. resourceIDs must list every member exactly once.
+func (r *CollectionRepository) Reorder(collectionID uint, resourceIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var items []models.CollectionItem
+		if err := tx.Where("collection_id = ?", collectionID).Find(&items).Error; err != nil {
+			return err
+		}
+		if len(items) != len(resourceIDs) {
+			return models.ErrInvalidCollectionOrder
+		}
+
+		itemIDs := make(map[uint]uint, len(items))
+		for _, item := range items {
+			itemIDs[item.ResourceID] = item.ID
+		}
+		for position, resourceID := range resourceIDs {
+			itemID, ok := itemIDs[resourceID]
+			if !ok {
+				return models.ErrInvalidCollectionOrder
+			}
+			// Each resource can only be placed once
+			delete(itemIDs, resourceID)
+			if err := tx.Model(&models.CollectionItem{}).Where("id = ?", itemID).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DeviceAuthorizationRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceAuthorizationRepository(db *gorm.DB) *DeviceAuthorizationRepository {
+	return &DeviceAuthorizationRepository{db: db}
+}
+
+func (r *DeviceAuthorizationRepository) Create(authorization *models.DeviceAuthorization) error {
+	// Finished and abandoned requests are never needed again, so prune them as we go
+	if err := r.db.Unscoped().Where("expires_at < ?", time.Now()).Delete(&models.DeviceAuthorization{}).Error; err != nil {
+		return err
+	}
+	return r.db.Create(authorization).Error
+}
+
+func (r *DeviceAuthorizationRepository) GetByDeviceCodeHash(hash string) (*models.DeviceAuthorization, error) {
+	var authorization models.DeviceAuthorization
+	if err := r.db.Where("device_code_hash = ?", hash).First(&authorization).Error; err != nil {
+		return nil, err
+	}
+	return &authorization, nil
+}
+
+// GetPendingByUserCode returns a request that is still waiting for the user
+func (r *DeviceAuthorizationRepository) GetPendingByUserCode(userCode string) (*models.DeviceAuthorization, error) {
+	var authorization models.DeviceAuthorization
+	err := r.db.Where("user_code = ? AND approved_at IS NULL AND denied_at IS NULL AND expires_at > ?", userCode, time.Now()).
+		First(&authorization).Error
+	if err != nil {
+		return nil, err
+	}
+	return &authorization, nil
+}
+
+// Decide records the user's answer. It reports false if the request was already decided.
+func (r *DeviceAuthorizationRepository) Decide(id, userID uint, approve bool) (bool, error) {
+	column := "denied_at"
+	if approve {
+		column = "approved_at"
+	}
+	result := r.db.Model(&models.DeviceAuthorization{}).
+		Where("id = ? AND approved_at IS NULL AND denied_at IS NULL", id).
+		Updates(map[string]interface{}{column: time.Now(), "user_id": userID})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecordPoll stores the time of a poll and the interval the device must wait before the next one
+func (r *DeviceAuthorizationRepository) RecordPoll(id uint, now time.Time, interval int) error {
+	return r.db.Model(&models.DeviceAuthorization{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"last_polled_at": now, "interval": interval}).Error
+}
+
+// Consume marks an approved request as used. It reports false if tokens were already issued for it.
+func (r *DeviceAuthorizationRepository) Consume(id uint) (bool, error) {
+	result := r.db.Model(&models.DeviceAuthorization{}).
+		Where("id = ? AND approved_at IS NOT NULL AND consumed_at IS NULL", id).
+		Update("consumed_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type FolderRepository struct {
+	db *gorm.DB
+}
+
+func NewFolderRepository(db *gorm.DB) *FolderRepository {
+	return &FolderRepository{db: db}
+}
+
+func (r *FolderRepository) GetByID(folderID uint) (*models.Folder, error) {
+	var folder models.Folder
+	if err := r.db.First(&folder, folderID).Error; err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// GetChildren returns the folders directly inside parentID, or the top-level folders when it is nil
+func (r *FolderRepository) GetChildren(userID uint, parentID *uint) ([]models.Folder, error) {
+	var folders []models.Folder
+	query := r.db.Where("user_id = ?", userID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	err := query.Order("name").Find(&folders).Error
+	return folders, err
+}
+
+// GetTree returns every folder of the user, parents before their children
+func (r *FolderRepository) GetTree(userID uint) ([]models.Folder, error) {
+	var folders []models.Folder
+	err := r.db.Where("user_id = ?", userID).Order("path").Find(&folders).Error
+	return folders, err
+}
+
+// GetChildByName finds the folder called name directly inside parentID
+func (r *FolderRepository) GetChildByName(userID uint, parentID *uint, name string) (*models.Folder, error) {
+	var folder models.Folder
+	query := r.db.Where("user_id = ? AND name = ?", userID, name)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	if err := query.First(&folder).Error; err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+// GetBreadcrumbs returns the folders from the top of the tree down to and including folder
+func (r *FolderRepository) GetBreadcrumbs(folder *models.Folder) ([]models.Folder, error) {
+	ids := folder.AncestorIDs()
+	var found []models.Folder
+	if err := r.db.Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Folder, len(found))
+	for _, f := range found {
+		byID[f.ID] = f
+	}
+	crumbs := make([]models.Folder, 0, len(ids))
+	for _, id := range ids {
+		if f, ok := byID[id]; ok {
+			crumbs = append(crumbs, f)
+		}
+	}
+	return crumbs, nil
+}
+
+// SubtreeDepth returns how many levels the folder's subtree spans, counting the folder itself
+func (r *FolderRepository) SubtreeDepth(folder *models.Folder) (int, error) {
+	var paths []string
+	if err := r.db.Model(&models.Folder{}).Scopes(inSubtree(folder)).Pluck("path", &paths).Error; err != nil {
+		return 0, err
+	}
+	depth := 1
+	for _, path := range paths {
+		if d := strings.Count(path, "/") - folder.Depth(); d > depth {
+			depth = d
+		}
+	}
+	return depth, nil
+}
+
+// CreateFolder stores the folder inside parent (nil for the top level) and fills in its path
+func (r *FolderRepository) CreateFolder(folder *models.Folder, parent *models.Folder) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// The path contains the folder's own ID, so it can only be set once the row exists
+		folder.Path = "/"
+		if err := tx.Create(folder).Error; err != nil {
+			return folderNameError(err)
+		}
+		folder.Path = models.FolderPath(parent, folder.ID)
+		return tx.Model(folder).Update("path", folder.Path).Error
+	})
+}
+
+func (r *FolderRepository) RenameFolder(folderID uint, name string) error {
+	return folderNameError(r.db.Model(&models.Folder{}).Where("id = ?", folderID).Update("name", name).Error)
+}
+
+// MoveFolder moves the folder and everything below it into newParent (nil for the top level)
+func (r *FolderRepository) MoveFolder(folder *models.Folder, newParent *models.Folder) error {
+	oldPath := folder.Path
+	oldSubtree := inSubtree(folder)
+	newPath := models.FolderPath(newParent, folder.ID)
+	var parentID *uint
+	if newParent != nil {
+		parentID = &newParent.ID
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Folder{}).Where("id = ?", folder.ID).Update("parent_id", parentID).Error; err != nil {
+			return folderNameError(err)
+		}
+		// Swap the old path prefix for the new one across the whole subtree
+		err := tx.Model(&models.Folder{}).
+			Scopes(oldSubtree).
+			Update("path", gorm.Expr("? || SUBSTR(path, ?)", newPath, len(oldPath)+1)).Error
+		if err != nil {
+			return err
+		}
+		folder.ParentID = parentID
+		folder.Path = newPath
+		return nil
+	})
+}
+
+// DeleteFolder deletes the folder and all of its subfolders. Resources inside them are either
+// moved to the top level or trashed (soft-deleted) along with the folders.
+func (r *FolderRepository) DeleteFolder(folder *models.Folder, trashResources bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		subtree := tx.Model(&models.Folder{}).Select("id").Scopes(inSubtree(folder))
+
+		if trashResources {
+			var resourceIDs []uint
+			if err := tx.Model(&models.Resource{}).Where("folder_id IN (?)", subtree).Pluck("id", &resourceIDs).Error; err != nil {
+				return err
+			}
+			if err := deleteResources(tx, resourceIDs); err != nil {
+				return err
+			}
+		} else if err := tx.Model(&models.Resource{}).Where("folder_id IN (?)", subtree).Update("folder_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Scopes(inSubtree(folder)).Delete(&models.Folder{}).Error
+	})
+}
+
+// inSubtree limits a folder query to the folder itself and everything below it
+func inSubtree(folder *models.Folder) func(*gorm.DB) *gorm.DB {
+	from, to := folder.SubtreeBounds()
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("folders.path >= ? AND folders.path < ?", from, to)
+	}
+}
+
+// folderNameError reports a sibling name clash caught by the unique indexes, which covers
+// requests that raced past the handler's own check
+func folderNameError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.ErrFolderNameTaken
+	}
+	return err
+}
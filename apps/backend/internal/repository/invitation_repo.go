@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type InvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+func (r *InvitationRepository) Create(invitation *models.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *InvitationRepository) GetByID(invitationID uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := r.db.First(&invitation, invitationID).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *InvitationRepository) GetByCodeHash(hash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := r.db.Where("code_hash = ?", hash).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// List returns a page of invitations, newest first. A nil inviterID lists everyone's.
+func (r *InvitationRepository) List(inviterID *uint, page, pageSize int) ([]models.Invitation, int64, error) {
+	var invitations []models.Invitation
+	var total int64
+
+	query := r.db.Model(&models.Invitation{})
+	if inviterID != nil {
+		query = query.Where("inviter_id = ?", *inviterID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&invitations).Error; err != nil {
+		return nil, 0, err
+	}
+	return invitations, total, nil
+}
+
+// GetInvitees returns the users who registered with the invitation
+func (r *InvitationRepository) GetInvitees(invitationID uint) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("invitation_id = ?", invitationID).Order("id").Find(&users).Error
+	return users, err
+}
+
+func (r *InvitationRepository) Revoke(invitationID uint) error {
+	return r.db.Model(&models.Invitation{}).
+		Where("id = ? AND revoked_at IS NULL", invitationID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RedeemAndCreateUser uses up one redemption of the invitation and creates the user it admits,
+// recording who invited them. Concurrent registrations cannot exceed MaxUses.
+func (r *InvitationRepository) RedeemAndCreateUser(invitation *models.Invitation, user *models.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND revoked_at IS NULL AND expires_at > ? AND uses < max_uses", invitation.ID, time.Now()).
+			Update("uses", gorm.Expr("uses + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrInvalidInvitation
+		}
+
+		user.InvitationID = &invitation.ID
+		user.InvitedByID = &invitation.InviterID
+		return tx.Create(user).Error
+	})
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LoginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) *LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+// GetByKeys returns the throttles that exist for the given keys
+func (r *LoginThrottleRepository) GetByKeys(keys ...string) ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	err := r.db.Where("throttle_key IN ?", keys).Find(&throttles).Error
+	return throttles, err
+}
+
+// RecordFailure counts a failed attempt and returns the updated throttle. Failures older than
+// window, or from before an expired lockout, are forgotten before counting.
+func (r *LoginThrottleRepository) RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("throttle_key = ?", key).First(&throttle).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			throttle = models.LoginThrottle{Key: key}
+		}
+
+		expiredLock := throttle.LockedUntil != nil && !throttle.IsLocked(now)
+		if expiredLock || now.Sub(throttle.LastFailureAt) > window {
+			throttle.Failures = 0
+			throttle.LockedUntil = nil
+		}
+		throttle.Failures++
+		throttle.LastFailureAt = now
+		return tx.Save(&throttle).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// Lock blocks the key until the given time
+func (r *LoginThrottleRepository) Lock(key string, until time.Time) error {
+	return r.db.Model(&models.LoginThrottle{}).Where("throttle_key = ?", key).Update("locked_until", until).Error
+}
+
+// Clear forgets every failure recorded for the key, lifting any lockout
+func (r *LoginThrottleRepository) Clear(key string) error {
+	return r.db.Where("throttle_key = ?", key).Delete(&models.LoginThrottle{}).Error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OneTimeTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewOneTimeTokenRepository(db *gorm.DB) *OneTimeTokenRepository {
+	return &OneTimeTokenRepository{db: db}
+}
+
+// CreateToken stores a new token and invalidates older unused tokens for the same user and purpose,
+// so only the most recent link works
+func (r *OneTimeTokenRepository) CreateToken(token *models.OneTimeToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.OneTimeToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// Peek returns an unused, unexpired token without consuming it
+func (r *OneTimeTokenRepository) Peek(hash string, purpose models.TokenPurpose) (*models.OneTimeToken, error) {
+	var token models.OneTimeToken
+	err := r.db.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, time.Now()).
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrInvalidOneTimeToken
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Consume marks a token as used and returns it. A token can only be consumed once,
+// even by concurrent requests.
+func (r *OneTimeTokenRepository) Consume(hash string, purpose models.TokenPurpose) (*models.OneTimeToken, error) {
+	token, err := r.Peek(hash, purpose)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := r.db.Model(&models.OneTimeToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, models.ErrInvalidOneTimeToken
+	}
+	token.UsedAt = &now
+	return token, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"devlink/internal/urlnorm"
+
+	"gorm.io/gorm"
+)
+
+// Repositories groups every repository so they can be wired up in one place
+type Repositories struct {
+	User          *UserRepository
+	Resource      *ResourceRepository
+	Token         *TokenRepository
+	AccessToken   *AccessTokenRepository
+	OneTimeToken  *OneTimeTokenRepository
+	RecoveryCode  *RecoveryCodeRepository
+	Identity      *IdentityRepository
+	Throttle      *LoginThrottleRepository
+	Audit         *AuditRepository
+	Device        *DeviceAuthorizationRepository
+	Invitation    *InvitationRepository
+	Collection    *CollectionRepository
+	Folder        *FolderRepository
+	SavedQuery    *SavedQueryRepository
+	ShareLink     *ShareLinkRepository
+	Workspace     *WorkspaceRepository
+	ResourceGrant *ResourceGrantRepository
+	Transfer      *TransferRepository
+}
+
+func NewRepositories(db *gorm.DB, urls *urlnorm.Normalizer) *Repositories {
+	return &Repositories{
+		User:          NewUserRepository(db),
+		Resource:      NewResourceRepository(db, urls),
+		Token:         NewTokenRepository(db),
+		AccessToken:   NewAccessTokenRepository(db),
+		OneTimeToken:  NewOneTimeTokenRepository(db),
+		RecoveryCode:  NewRecoveryCodeRepository(db),
+		Identity:      NewIdentityRepository(db),
+		Throttle:      NewLoginThrottleRepository(db),
+		Audit:         NewAuditRepository(db),
+		Device:        NewDeviceAuthorizationRepository(db),
+		Invitation:    NewInvitationRepository(db),
+		Collection:    NewCollectionRepository(db),
+		Folder:        NewFolderRepository(db),
+		SavedQuery:    NewSavedQueryRepository(db),
+		ShareLink:     NewShareLinkRepository(db),
+		Workspace:     NewWorkspaceRepository(db),
+		ResourceGrant: NewResourceGrantRepository(db),
+		Transfer:      NewTransferRepository(db),
+	}
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ResourceGrantRepository struct {
+	db *gorm.DB
+}
+
+func NewResourceGrantRepository(db *gorm.DB) *ResourceGrantRepository {
+	return &ResourceGrantRepository{db: db}
+}
+
+// ResourceGrantDetail is a grant together with the grantee's username
+type ResourceGrantDetail struct {
+	models.ResourceGrant
+	Username string
+}
+
+// SharedResource is a resource seen by a user it was shared with
+type SharedResource struct {
+	models.Resource
+	Permission  models.GrantPermission
+	GrantedByID uint
+}
+
+func (r *ResourceGrantRepository) Get(resourceID, userID uint) (*models.ResourceGrant, error) {
+	var grant models.ResourceGrant
+	if err := r.db.Where("resource_id = ? AND user_id = ?", resourceID, userID).First(&grant).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// GetByResourceID returns everyone the resource is shared with
+func (r *ResourceGrantRepository) GetByResourceID(resourceID uint) ([]ResourceGrantDetail, error) {
+	var grants []ResourceGrantDetail
+	err := r.db.Model(&models.ResourceGrant{}).
+		Select("resource_grants.*, users.username").
+		Joins("JOIN users ON users.id = resource_grants.user_id").
+		Where("resource_grants.resource_id = ?", resourceID).
+		Order("resource_grants.id").
+		Scan(&grants).Error
+	return grants, err
+}
+
+// Upsert shares the resource with the user, or changes the permission of an existing grant
+func (r *ResourceGrantRepository) Upsert(grant *models.ResourceGrant) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "resource_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"permission", "granted_by_id", "updated_at"}),
+	}).Create(grant).Error
+}
+
+func (r *ResourceGrantRepository) Update(grant *models.ResourceGrant) error {
+	return r.db.Save(grant).Error
+}
+
+func (r *ResourceGrantRepository) Delete(grant *models.ResourceGrant) error {
+	return r.db.Delete(grant).Error
+}
+
+// GetSharedWith returns a page of the resources shared with the user, most recently shared first
+func (r *ResourceGrantRepository) GetSharedWith(userID uint, page, pageSize int) ([]SharedResource, int64, error) {
+	var resources []SharedResource
+	var total int64
+
+	query := r.db.Model(&models.Resource{}).
+		Joins("JOIN resource_grants ON resource_grants.resource_id = resources.id").
+		Where("resource_grants.user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	err := query.Select("resources.*, resource_grants.permission, resource_grants.granted_by_id").
+		Order("resource_grants.id DESC").
+		Offset(offset).Limit(pageSize).
+		Scan(&resources).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return resources, total, nil
+}
@@ -1,17 +1,25 @@
 package repository
 
 import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
 	"devlink/internal/models"
+	"devlink/internal/urlnorm"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ResourceRepository struct {
-	db *gorm.DB
+	db   *gorm.DB
+	urls *urlnorm.Normalizer
 }
 
-func NewResourceRepository(db *gorm.DB) *ResourceRepository {
-	return &ResourceRepository{db: db}
+func NewResourceRepository(db *gorm.DB, urls *urlnorm.Normalizer) *ResourceRepository {
+	return &ResourceRepository{db: db, urls: urls}
 }
 
 func (r *ResourceRepository) GetByID(resourceID uint) (*models.Resource, error) {
@@ -22,82 +30,494 @@ func (r *ResourceRepository) GetByID(resourceID uint) (*models.Resource, error)
 	return &resource, nil
 }
 
-func (r *ResourceRepository) GetByUserID(userID uint, page, pageSize int) ([]models.Resource, int64, error) {
+// FolderScope limits a listing to one folder; a nil Folder means the top level.
+// Recursive includes every folder below it as well.
+type FolderScope struct {
+	Folder    *models.Folder
+	Recursive bool
+}
+
+// ResourceFilter narrows a resource listing; empty fields match everything.
+// UserID selects that user's personal library and WorkspaceID a workspace's library instead.
+// With neither set every owner matches, so listings across owners must also set Visibility.
+// IncludeShared adds the resources other users shared with UserID.
+type ResourceFilter struct {
+	UserID        uint
+	WorkspaceID   *uint
+	IncludeShared bool
+	Visibility    models.ResourceVisibility
+	Query         string // matched against title, description and URL
+	Tags          []string
+	Type          models.ResourceType
+	Category      models.LinkCategory
+	Language      string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Folder        *FolderScope
+}
+
+func (r *ResourceRepository) filtered(filter ResourceFilter) *gorm.DB {
+	query := r.db.Model(&models.Resource{})
+	switch {
+	case filter.WorkspaceID != nil:
+		query = query.Where("resources.workspace_id = ?", *filter.WorkspaceID)
+	case filter.UserID != 0 && filter.IncludeShared:
+		// One uncorrelated IN list built from the user_id indexes of both tables. SQLite then looks
+		// resources up by id; an OR here lets the soft-delete clause pick the deleted_at index and
+		// walk every live resource.
+		query = query.Where("resources.id IN (SELECT id FROM resources WHERE user_id = ? AND workspace_id IS NULL "+
+			"UNION ALL SELECT resource_id FROM resource_grants WHERE user_id = ?)",
+			filter.UserID, filter.UserID)
+	case filter.UserID != 0:
+		query = query.Where("resources.user_id = ? AND resources.workspace_id IS NULL", filter.UserID)
+	}
+	for _, condition := range criteria(filter) {
+		query = query.Where(condition.SQL, condition.Vars...)
+	}
+	if scope := filter.Folder; scope != nil {
+		switch {
+		case scope.Folder == nil && !scope.Recursive:
+			query = query.Where("resources.folder_id IS NULL")
+		case scope.Folder != nil && !scope.Recursive:
+			query = query.Where("resources.folder_id = ?", scope.Folder.ID)
+		case scope.Folder != nil:
+			// The materialized path finds the whole subtree with one range scan over the path index
+			query = query.Joins("JOIN folders ON folders.id = resources.folder_id AND folders.deleted_at IS NULL").
+				Scopes(inSubtree(scope.Folder))
+		}
+	}
+	return query
+}
+
+// criteria returns the conditions the filter puts on each resource, leaving out which library
+// and folder it searches
+func criteria(filter ResourceFilter) []clause.Expr {
+	var conditions []clause.Expr
+	add := func(sql string, vars ...interface{}) {
+		conditions = append(conditions, clause.Expr{SQL: sql, Vars: vars})
+	}
+	if filter.Visibility != "" {
+		add("resources.visibility = ?", filter.Visibility)
+	}
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		add("(resources.title LIKE ? OR resources.description LIKE ? OR resources.url LIKE ?)", like, like, like)
+	}
+	for _, tag := range filter.Tags {
+		add("resources.tags LIKE ?", "%"+tag+"%")
+	}
+	if filter.Type != "" {
+		add("resources.type = ?", filter.Type)
+	}
+	if filter.Category != "" {
+		add("resources.category = ?", filter.Category)
+	}
+	if filter.Language != "" {
+		add("LOWER(resources.language) = LOWER(?)", filter.Language)
+	}
+	if filter.CreatedAfter != nil {
+		add("resources.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		add("resources.created_at < ?", *filter.CreatedBefore)
+	}
+	return conditions
+}
+
+// List returns a page of the resources matching the filter, oldest first
+func (r *ResourceRepository) List(filter ResourceFilter, page, pageSize int) ([]models.Resource, int64, error) {
 	var resources []models.Resource
 	var total int64
 
 	// Get total count
-	if err := r.db.Model(&models.Resource{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := r.filtered(filter).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Get paginated results
 	offset := (page - 1) * pageSize
-	if err := r.db.Where("user_id = ?", userID).Offset(offset).Limit(pageSize).Find(&resources).Error; err != nil {
+	if err := r.filtered(filter).Order("resources.id").Offset(offset).Limit(pageSize).Find(&resources).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return resources, total, nil
 }
 
+// Count returns how many resources match the filter
+func (r *ResourceRepository) Count(filter ResourceFilter) (int64, error) {
+	var total int64
+	err := r.filtered(filter).Count(&total).Error
+	return total, err
+}
+
+// CountEach counts the resources in library that match each filter, with a single pass over the
+// library instead of one query per filter. Only the criteria of the filters are used; their
+// library and folder scope is ignored.
+func (r *ResourceRepository) CountEach(library ResourceFilter, filters []ResourceFilter) ([]int64, error) {
+	counts := make([]int64, len(filters))
+	if len(filters) == 0 {
+		return counts, nil
+	}
+	columns := make([]string, len(filters))
+	var vars []interface{}
+	for i, filter := range filters {
+		conditions := []string{"1 = 1"}
+		for _, condition := range criteria(filter) {
+			conditions = append(conditions, condition.SQL)
+			vars = append(vars, condition.Vars...)
+		}
+		columns[i] = "COUNT(CASE WHEN " + strings.Join(conditions, " AND ") + " THEN 1 END)"
+	}
+
+	row := r.filtered(library).
+		Clauses(clause.Select{Expression: clause.Expr{SQL: strings.Join(columns, ", "), Vars: vars}}).
+		Row()
+	dest := make([]interface{}, len(counts))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *ResourceRepository) GetByUserID(userID uint, page, pageSize int) ([]models.Resource, int64, error) {
+	return r.List(ResourceFilter{UserID: userID}, page, pageSize)
+}
+
+// MoveToFolder files the resource in a folder, or at the top level when folderID is nil
+func (r *ResourceRepository) MoveToFolder(resourceID uint, folderID *uint) error {
+	return r.db.Model(&models.Resource{}).Where("id = ?", resourceID).Update("folder_id", folderID).Error
+}
+
+// CreateResource links the resource to the canonical form of its URL and saves it. If its library
+// already has the link the resource is not saved and a *models.DuplicateResourceError is returned.
 func (r *ResourceRepository) CreateResource(resource *models.Resource) error {
-	return r.db.Create(resource).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := linkResource(tx, r.urls, resource); err != nil {
+			return err
+		}
+		return tx.Create(resource).Error
+	})
+	return r.saveError(resource, err)
 }
 
+// UpdateResource saves the resource, relinking it if its URL changed. Like CreateResource it
+// refuses a link the library already has. A resource left unlinked as a duplicate by the
+// backfill keeps saving as long as its URL stays the same.
 func (r *ResourceRepository) UpdateResource(resource *models.Resource) error {
-	return r.db.Save(resource).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var storedURLs []string
+		if err := tx.Model(&models.Resource{}).Where("id = ?", resource.ID).Pluck("url", &storedURLs).Error; err != nil {
+			return err
+		}
+		if len(storedURLs) == 0 || storedURLs[0] != resource.URL {
+			if err := linkResource(tx, r.urls, resource); err != nil {
+				return err
+			}
+		}
+		return tx.Save(resource).Error
+	})
+	return r.saveError(resource, err)
 }
 
-func (r *ResourceRepository) DeleteResource(resourceID uint) error {
-	return r.db.Delete(&models.Resource{}, resourceID).Error
+// saveError reports a save that lost a race for the library's unique link index the same way
+// linkResource reports a duplicate it sees up front
+func (r *ResourceRepository) saveError(resource *models.Resource, err error) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) || resource.CanonicalLinkID == nil {
+		return err
+	}
+	existingID, lookupErr := linkHolder(r.db, resource)
+	if lookupErr != nil || existingID == 0 {
+		return err
+	}
+	return duplicateResourceError(resource, existingID)
 }
 
-func (r *ResourceRepository) SearchResources(query string, userID uint, page, pageSize int) ([]models.Resource, int64, error) {
-	var resources []models.Resource
-	var total int64
+// linkResource points the resource at the canonical link for its URL, creating the link on first
+// use, and checks that no other resource in the same library has it
+func linkResource(tx *gorm.DB, urls *urlnorm.Normalizer, resource *models.Resource) error {
+	if resource.URL == "" {
+		resource.CanonicalLinkID = nil
+		return nil
+	}
+	canonicalURL, err := urls.Canonical(resource.URL)
+	if err != nil {
+		return err
+	}
+	link, err := canonicalLink(tx, canonicalURL)
+	if err != nil {
+		return err
+	}
+	resource.CanonicalLinkID = &link.ID
 
-	// Build search query
-	searchQuery := r.db.Model(&models.Resource{}).
-		Where("user_id = ? AND (title LIKE ? OR description LIKE ? OR url LIKE ?)",
-			userID,
-			"%"+query+"%",
-			"%"+query+"%",
-			"%"+query+"%")
+	existingID, err := linkHolder(tx, resource)
+	if err != nil || existingID == 0 {
+		return err
+	}
+	return duplicateResourceError(resource, existingID)
+}
 
-	// Get total count
-	if err := searchQuery.Count(&total).Error; err != nil {
-		return nil, 0, err
+// linkHolder returns the ID of another resource in the same library with the resource's
+// canonical link, or 0 if there is none
+func linkHolder(tx *gorm.DB, resource *models.Resource) (uint, error) {
+	var existingIDs []uint
+	if err := libraryOf(tx.Model(&models.Resource{}), resource.UserID, resource.WorkspaceID).
+		Where("canonical_link_id = ? AND id <> ?", resource.CanonicalLinkID, resource.ID).
+		Limit(1).Pluck("id", &existingIDs).Error; err != nil {
+		return 0, err
 	}
+	if len(existingIDs) == 0 {
+		return 0, nil
+	}
+	return existingIDs[0], nil
+}
 
-	// Get paginated results
-	offset := (page - 1) * pageSize
-	if err := searchQuery.Offset(offset).Limit(pageSize).Find(&resources).Error; err != nil {
-		return nil, 0, err
+func duplicateResourceError(resource *models.Resource, existingID uint) *models.DuplicateResourceError {
+	duplicate := &models.DuplicateResourceError{Err: models.ErrResourceAlreadySaved, ExistingResourceID: existingID}
+	if resource.WorkspaceID != nil {
+		duplicate.Err = models.ErrWorkspaceAlreadySaved
 	}
+	return duplicate
+}
 
-	return resources, total, nil
+// canonicalLink returns the link for a canonical URL, creating it if nobody saved it before
+func canonicalLink(tx *gorm.DB, canonicalURL string) (*models.CanonicalLink, error) {
+	link := models.CanonicalLink{URL: canonicalURL}
+	if parsed, err := url.Parse(canonicalURL); err == nil {
+		link.Host = parsed.Hostname()
+	}
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "url"}}, DoNothing: true}).Create(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	if link.ID == 0 {
+		if err := tx.Where("url = ?", canonicalURL).First(&link).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &link, nil
 }
 
-func (r *ResourceRepository) GetByTags(tags []string, userID uint, page, pageSize int) ([]models.Resource, int64, error) {
-	var resources []models.Resource
-	var total int64
+// libraryOf limits a resource query to the workspace's library, or the user's personal one when
+// workspaceID is nil
+func libraryOf(query *gorm.DB, userID uint, workspaceID *uint) *gorm.DB {
+	if workspaceID != nil {
+		return query.Where("workspace_id = ?", *workspaceID)
+	}
+	return query.Where("user_id = ? AND workspace_id IS NULL", userID)
+}
 
-	// Build tag search query
-	query := r.db.Model(&models.Resource{}).Where("user_id = ?", userID)
-	for _, tag := range tags {
-		query = query.Where("tags LIKE ?", "%"+tag+"%")
+// LinkLookup is what the instance knows about a URL from one user's point of view
+type LinkLookup struct {
+	URL        string
+	SaveCount  int64
+	ResourceID *uint
+}
+
+// LookupLink reports how many resources across the instance point at the URL's canonical link
+// and which resource in the library holds it, if any
+func (r *ResourceRepository) LookupLink(rawURL string, userID uint, workspaceID *uint) (*LinkLookup, error) {
+	canonicalURL, err := r.urls.Canonical(rawURL)
+	if err != nil {
+		return nil, err
 	}
+	lookup := &LinkLookup{URL: canonicalURL}
 
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	var link models.CanonicalLink
+	err = r.db.Where("url = ?", lookup.URL).First(&link).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return lookup, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Get paginated results
-	offset := (page - 1) * pageSize
-	if err := query.Offset(offset).Limit(pageSize).Find(&resources).Error; err != nil {
-		return nil, 0, err
+	if err := r.db.Model(&models.Resource{}).Where("canonical_link_id = ?", link.ID).Count(&lookup.SaveCount).Error; err != nil {
+		return nil, err
 	}
 
-	return resources, total, nil
-} 
\ No newline at end of file
+	var ids []uint
+	if err := libraryOf(r.db.Model(&models.Resource{}), userID, workspaceID).
+		Where("canonical_link_id = ?", link.ID).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		lookup.ResourceID = &ids[0]
+	}
+	return lookup, nil
+}
+
+// URLReport sums up a pass over stored resource URLs
+type URLReport struct {
+	Checked    int
+	Normalized int   // URL rewritten to its normalized form
+	Linked     int   // pointed at a different canonical link
+	Pruned     int64 // canonical links no resource points at any more
+	Invalid    []uint
+	Duplicates []URLDuplicate
+}
+
+// URLDuplicate is a resource left without a canonical link because its library already holds one
+type URLDuplicate struct {
+	ResourceID         uint
+	ExistingResourceID uint
+	UserID             uint
+	WorkspaceID        *uint
+	URL                string
+}
+
+var errDryRun = errors.New("dry run")
+
+// BackfillCanonicalLinks links resources saved before canonical links existed. A resource whose
+// library already holds the same link, or whose URL is not absolute, is left unlinked.
+func (r *ResourceRepository) BackfillCanonicalLinks() (*URLReport, error) {
+	report := &URLReport{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return r.relinkURLs(tx, tx.Where("canonical_link_id IS NULL AND url <> ''"), false, report)
+	})
+	return report, err
+}
+
+// RenormalizeURLs runs every stored URL through the normalizer again, keeping the first URL seen
+// as RawURL, relinks the resources and deletes canonical links left unused. It runs in one
+// transaction, which is rolled back when dryRun is set so the report shows what would change.
+func (r *ResourceRepository) RenormalizeURLs(dryRun bool) (*URLReport, error) {
+	report := &URLReport{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.relinkURLs(tx, tx.Where("url <> ''"), true, report); err != nil {
+			return err
+		}
+		// Deleted resources still count so restoring one cannot break its link
+		used := tx.Unscoped().Model(&models.Resource{}).Select("canonical_link_id").Where("canonical_link_id IS NOT NULL")
+		result := tx.Where("id NOT IN (?)", used).Delete(&models.CanonicalLink{})
+		if result.Error != nil {
+			return result.Error
+		}
+		report.Pruned = result.RowsAffected
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return report, err
+}
+
+func (r *ResourceRepository) relinkURLs(tx, query *gorm.DB, renormalize bool, report *URLReport) error {
+	var resources []models.Resource
+	return query.Order("id").FindInBatches(&resources, 200, func(_ *gorm.DB, batch int) error {
+		for i := range resources {
+			resource := &resources[i]
+			report.Checked++
+			updates := map[string]interface{}{}
+
+			// URLs that do not normalize are reported and unlinked below
+			if normalized, err := r.urls.Normalize(resource.URL); renormalize && err == nil {
+				if resource.RawURL == "" {
+					resource.RawURL = resource.URL
+					updates["raw_url"] = resource.RawURL
+				}
+				if normalized != resource.URL {
+					resource.URL = normalized
+					updates["url"] = normalized
+					report.Normalized++
+				}
+			}
+
+			previousLinkID := resource.CanonicalLinkID
+			err := linkResource(tx, r.urls, resource)
+			var duplicate *models.DuplicateResourceError
+			switch {
+			case errors.As(err, &duplicate):
+				resource.CanonicalLinkID = nil
+				report.Duplicates = append(report.Duplicates, URLDuplicate{
+					ResourceID:         resource.ID,
+					ExistingResourceID: duplicate.ExistingResourceID,
+					UserID:             resource.UserID,
+					WorkspaceID:        resource.WorkspaceID,
+					URL:                resource.URL,
+				})
+			case errors.Is(err, urlnorm.ErrInvalidURL):
+				resource.CanonicalLinkID = nil
+				report.Invalid = append(report.Invalid, resource.ID)
+			case err != nil:
+				return err
+			}
+			if !sameID(previousLinkID, resource.CanonicalLinkID) {
+				updates["canonical_link_id"] = resource.CanonicalLinkID
+				if resource.CanonicalLinkID != nil {
+					report.Linked++
+				}
+			}
+
+			if len(updates) > 0 {
+				if err := tx.Model(resource).UpdateColumns(updates).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}).Error
+}
+
+func sameID(a, b *uint) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+// DeleteResource deletes the resource and takes it out of every collection it was in
+func (r *ResourceRepository) DeleteResource(resourceID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteResources(tx, []uint{resourceID})
+	})
+}
+
+// deleteResources deletes resources inside a transaction, removing them from collections
+func deleteResources(tx *gorm.DB, resourceIDs []uint) error {
+	if len(resourceIDs) == 0 {
+		return nil
+	}
+	if err := removeFromCollections(tx, resourceIDs); err != nil {
+		return err
+	}
+	if err := tx.Where("resource_id IN ?", resourceIDs).Delete(&models.ResourceGrant{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("resource_id IN ?", resourceIDs).Delete(&models.ShareLink{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.Resource{}, resourceIDs).Error
+}
+
+// removeFromCollections takes the resources out of every collection they are in
+func removeFromCollections(tx *gorm.DB, resourceIDs []uint) error {
+	var items []models.CollectionItem
+	if err := tx.Where("resource_id IN ?", resourceIDs).Find(&items).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		// Close the gap so positions stay contiguous
+		if err := tx.Model(&models.CollectionItem{}).
+			Where("collection_id = ? AND position > ?", item.CollectionID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchResources searches the user's personal library and the resources shared with them
+func (r *ResourceRepository) SearchResources(query string, userID uint, page, pageSize int) ([]models.Resource, int64, error) {
+	return r.List(ResourceFilter{UserID: userID, IncludeShared: true, Query: query}, page, pageSize)
+}
+
+// GetByTags filters the user's personal library and the resources shared with them by tag
+func (r *ResourceRepository) GetByTags(tags []string, userID uint, page, pageSize int) ([]models.Resource, int64, error) {
+	return r.List(ResourceFilter{UserID: userID, IncludeShared: true, Tags: tags}, page, pageSize)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SavedQueryRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedQueryRepository(db *gorm.DB) *SavedQueryRepository {
+	return &SavedQueryRepository{db: db}
+}
+
+func (r *SavedQueryRepository) GetByID(savedQueryID uint) (*models.SavedQuery, error) {
+	var savedQuery models.SavedQuery
+	if err := r.db.First(&savedQuery, savedQueryID).Error; err != nil {
+		return nil, err
+	}
+	return &savedQuery, nil
+}
+
+// GetByUserID returns a page of the user's saved queries, pinned ones first
+func (r *SavedQueryRepository) GetByUserID(userID uint, page, pageSize int) ([]models.SavedQuery, int64, error) {
+	var savedQueries []models.SavedQuery
+	var total int64
+
+	query := r.db.Model(&models.SavedQuery{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	err := query.Order("pinned_at IS NULL, pinned_at DESC, name").
+		Offset(offset).Limit(pageSize).
+		Find(&savedQueries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return savedQueries, total, nil
+}
+
+func (r *SavedQueryRepository) CreateSavedQuery(savedQuery *models.SavedQuery) error {
+	return r.db.Create(savedQuery).Error
+}
+
+func (r *SavedQueryRepository) UpdateSavedQuery(savedQuery *models.SavedQuery) error {
+	return r.db.Save(savedQuery).Error
+}
+
+func (r *SavedQueryRepository) DeleteSavedQuery(savedQueryID uint) error {
+	return r.db.Delete(&models.SavedQuery{}, savedQueryID).Error
+}
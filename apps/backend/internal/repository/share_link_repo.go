@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ShareLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewShareLinkRepository(db *gorm.DB) *ShareLinkRepository {
+	return &ShareLinkRepository{db: db}
+}
+
+func (r *ShareLinkRepository) Create(link *models.ShareLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *ShareLinkRepository) GetByID(linkID uint) (*models.ShareLink, error) {
+	var link models.ShareLink
+	if err := r.db.First(&link, linkID).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *ShareLinkRepository) GetByTokenHash(hash string) (*models.ShareLink, error) {
+	var link models.ShareLink
+	if err := r.db.Where("token_hash = ?", hash).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// GetByResourceID returns every share link of the resource, newest first
+func (r *ShareLinkRepository) GetByResourceID(resourceID uint) ([]models.ShareLink, error) {
+	var links []models.ShareLink
+	err := r.db.Where("resource_id = ?", resourceID).Order("id DESC").Find(&links).Error
+	return links, err
+}
+
+func (r *ShareLinkRepository) Revoke(linkID uint) error {
+	return r.db.Model(&models.ShareLink{}).
+		Where("id = ? AND revoked_at IS NULL", linkID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RecordView counts an access through the link
+func (r *ShareLinkRepository) RecordView(linkID uint) error {
+	return r.db.Model(&models.ShareLink{}).Where("id = ?", linkID).Updates(map[string]interface{}{
+		"views":            gorm.Expr("views + 1"),
+		"last_accessed_at": time.Now(),
+	}).Error
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"time"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// StartSession stores the session for a new login together with its first refresh token
+func (r *TokenRepository) StartSession(session *models.Session, token *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *TokenRepository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken marks the old token as used and stores its replacement atomically.
+// It fails with ErrInvalidRefreshToken if another request rotated the old token first.
+func (r *TokenRepository) RotateRefreshToken(old *models.RefreshToken, next *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", old.ID).
+			Update("rotated_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrInvalidRefreshToken
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		// The session lives as long as its newest refresh token
+		return tx.Model(&models.Session{}).
+			Where("family_id = ? AND revoked_at IS NULL", next.FamilyID).
+			Updates(map[string]interface{}{"expires_at": next.ExpiresAt, "last_seen_at": now}).Error
+	})
+}
+
+// RevokeFamily ends a session and revokes every refresh token issued from the same login
+func (r *TokenRepository) RevokeFamily(familyID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.Session{}).
+			Where("family_id = ? AND revoked_at IS NULL", familyID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", familyID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeAllForUser ends every session and revokes every refresh token the user holds
+func (r *TokenRepository) RevokeAllForUser(userID uint) error {
+	return r.RevokeAllForUserExcept(userID, "")
+}
+
+// RevokeAllForUserExcept is RevokeAllForUser that keeps one session, e.g. the caller's own
+func (r *TokenRepository) RevokeAllForUserExcept(userID uint, keepFamilyID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.Session{}).
+			Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepFamilyID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepFamilyID).
+			Update("revoked_at", now).Error
+	})
+}
+
+func (r *TokenRepository) CreateSession(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *TokenRepository) GetSessionByFamily(familyID string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.Where("family_id = ?", familyID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *TokenRepository) GetSessionByID(userID, sessionID uint) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveSessions returns the user's sessions that can still be used, most recently seen first
+func (r *TokenRepository) GetActiveSessions(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// TouchSession records that the session was just used, from ip
+func (r *TokenRepository) TouchSession(sessionID uint, ip string, now time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ?", sessionID).
+		Updates(map[string]interface{}{"last_seen_at": now, "ip": ip}).Error
+}
+
+// RevokeJTI adds an access token to the revocation list until it expires
+func (r *TokenRepository) RevokeJTI(jti string, expiresAt time.Time) error {
+	// Expired entries can never match a valid token, so prune them as we go
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where(models.RevokedToken{JTI: jti}).
+		Attrs(models.RevokedToken{ExpiresAt: expiresAt}).
+		FirstOrCreate(&models.RevokedToken{}).Error
+}
+
+// ClaimJTI records a single-use token's jti and reports whether this call was the first to do so
+func (r *TokenRepository) ClaimJTI(jti string, expiresAt time.Time) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedToken{JTI: jti, ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TokenRepository) IsJTIRevoked(jti string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"time"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TransferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// TransferConflictError reports the resources that stopped a transfer from being accepted
+type TransferConflictError struct {
+	Conflicts []models.TransferConflict
+}
+
+func (e *TransferConflictError) Error() string {
+	return models.ErrTransferConflicts.Error()
+}
+
+func (e *TransferConflictError) Unwrap() error {
+	return models.ErrTransferConflicts
+}
+
+func (r *TransferRepository) Create(transfer *models.ResourceTransfer) error {
+	return r.db.Create(transfer).Error
+}
+
+func (r *TransferRepository) GetByID(transferID uint) (*models.ResourceTransfer, error) {
+	var transfer models.ResourceTransfer
+	if err := r.db.First(&transfer, transferID).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// List returns a page of the transfers the user received, or sent when incoming is false, newest first
+func (r *TransferRepository) List(userID uint, incoming bool, page, pageSize int) ([]models.ResourceTransfer, int64, error) {
+	var transfers []models.ResourceTransfer
+	var total int64
+
+	query := r.db.Model(&models.ResourceTransfer{})
+	if incoming {
+		query = query.Where("to_user_id = ?", userID)
+	} else {
+		query = query.Where("from_user_id = ?", userID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&transfers).Error; err != nil {
+		return nil, 0, err
+	}
+	return transfers, total, nil
+}
+
+// CountPersonal counts how many of the given resources are in the user's personal library. With
+// no IDs it counts the whole library.
+func (r *TransferRepository) CountPersonal(userID uint, resourceIDs []uint) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.Resource{}).Where("user_id = ? AND workspace_id IS NULL", userID)
+	if resourceIDs != nil {
+		query = query.Where("id IN ?", resourceIDs)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// Respond declines or cancels a pending transfer
+func (r *TransferRepository) Respond(transfer *models.ResourceTransfer, status models.TransferStatus) error {
+	now := time.Now()
+	result := r.db.Model(&models.ResourceTransfer{}).
+		Where("id = ? AND status = ? AND expires_at > ?", transfer.ID, models.TransferStatusPending, now).
+		Updates(map[string]interface{}{"status": status, "responded_at": now})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrTransferNotPending
+	}
+	transfer.Status = status
+	transfer.RespondedAt = &now
+	return nil
+}
+
+// Conflicts returns the resources in the transfer whose URL the recipient has already saved
+func (r *TransferRepository) Conflicts(transfer *models.ResourceTransfer) ([]models.TransferConflict, error) {
+	ids, err := transferResourceIDs(r.db, transfer)
+	if err != nil {
+		return nil, err
+	}
+	return transferConflicts(r.db, ids, transfer.ToUserID)
+}
+
+// Accept moves the resources to the recipient in one transaction. Resources the sender no longer
+// owns are left out. Conflicting URLs abort the transfer with a *TransferConflictError unless
+// the policy is to skip them. Moved resources leave the sender's folders and collections.
+func (r *TransferRepository) Accept(transfer *models.ResourceTransfer, conflictPolicy models.TransferConflictPolicy) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the transfer first so it cannot be accepted twice
+		result := tx.Model(&models.ResourceTransfer{}).
+			Where("id = ? AND status = ? AND expires_at > ?", transfer.ID, models.TransferStatusPending, now).
+			Updates(map[string]interface{}{"status": models.TransferStatusAccepted, "responded_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrTransferNotPending
+		}
+
+		ids, err := transferResourceIDs(tx, transfer)
+		if err != nil {
+			return err
+		}
+		conflicts, err := transferConflicts(tx, ids, transfer.ToUserID)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 && conflictPolicy != models.TransferConflictSkip {
+			return &TransferConflictError{Conflicts: conflicts}
+		}
+		skipped := make(map[uint]bool, len(conflicts))
+		for _, conflict := range conflicts {
+			skipped[conflict.ResourceID] = true
+		}
+		moving := make([]uint, 0, len(ids))
+		for _, id := range ids {
+			if !skipped[id] {
+				moving = append(moving, id)
+			}
+		}
+
+		if len(moving) > 0 {
+			if err := removeFromCollections(tx, moving); err != nil {
+				return err
+			}
+			// Sharing was the sender's decision, so the recipient starts without any: grants to
+			// third parties and share links are withdrawn, and grants the recipient held are
+			// redundant now that they own the resources
+			if err := tx.Where("resource_id IN ?", moving).Delete(&models.ResourceGrant{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.ShareLink{}).Where("resource_id IN ? AND revoked_at IS NULL", moving).
+				Update("revoked_at", now).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Resource{}).Where("id IN ?", moving).
+				Updates(map[string]interface{}{"user_id": transfer.ToUserID, "folder_id": nil}).Error; err != nil {
+				return err
+			}
+		}
+
+		transfer.Status = models.TransferStatusAccepted
+		transfer.RespondedAt = &now
+		transfer.MovedCount = len(moving)
+		transfer.SkippedCount = len(skipped)
+		return tx.Model(transfer).Updates(map[string]interface{}{
+			"moved_count":   transfer.MovedCount,
+			"skipped_count": transfer.SkippedCount,
+		}).Error
+	})
+}
+
+// transferResourceIDs returns the resources the transfer covers that are still in the sender's
+// personal library
+func transferResourceIDs(tx *gorm.DB, transfer *models.ResourceTransfer) ([]uint, error) {
+	var ids []uint
+	query := tx.Model(&models.Resource{}).
+		Where("user_id = ? AND workspace_id IS NULL", transfer.FromUserID)
+	if !transfer.WholeAccount {
+		selected := transfer.ResourceIDList()
+		if len(selected) == 0 {
+			return nil, nil
+		}
+		query = query.Where("id IN ?", selected)
+	}
+	err := query.Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// transferConflicts finds the resources whose link is already in the recipient's personal library
+func transferConflicts(tx *gorm.DB, resourceIDs []uint, toUserID uint) ([]models.TransferConflict, error) {
+	conflicts := []models.TransferConflict{}
+	if len(resourceIDs) == 0 {
+		return conflicts, nil
+	}
+	err := tx.Table("resources AS incoming").
+		Select("incoming.id AS resource_id, existing.id AS existing_resource_id, incoming.url AS url").
+		Joins("JOIN resources AS existing ON existing.canonical_link_id = incoming.canonical_link_id "+
+			"AND existing.user_id = ? AND existing.workspace_id IS NULL AND existing.deleted_at IS NULL", toUserID).
+		Where("incoming.id IN ?", resourceIDs).
+		Order("incoming.id").
+		Scan(&conflicts).Error
+	return conflicts, err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"devlink/internal/models"
+	"errors"
 
 	"gorm.io/gorm"
 )
@@ -30,6 +31,14 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+func (r *UserRepository) GetByUsername(username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *UserRepository) GetAllUsers() ([]models.User, error) {
 	var users []models.User
 	if err := r.db.Find(&users).Error; err != nil {
@@ -56,6 +65,64 @@ func (r *UserRepository) GetAllUsersPaginated(page, pageSize int) ([]models.User
 	return users, total, nil
 }
 
+// UserFilter narrows the admin user listing; empty fields match everyone
+type UserFilter struct {
+	Query     string // matched against username and email
+	Role      models.Role
+	Suspended *bool
+}
+
+// ListUsers returns a page of users matching the filter, oldest first
+func (r *UserRepository) ListUsers(filter UserFilter, page, pageSize int) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	query := r.db.Model(&models.User{})
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("username LIKE ? OR email LIKE ?", like, like)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			query = query.Where("suspended_at IS NOT NULL")
+		} else {
+			query = query.Where("suspended_at IS NULL")
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	if err := query.Order("id").Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// CountActiveAdmins counts admins who are not suspended
+func (r *UserRepository) CountActiveAdmins() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).
+		Where("role = ? AND suspended_at IS NULL", models.RoleAdmin).
+		Count(&count).Error
+	return count, err
+}
+
+// PromoteToAdmin makes the users with the given verified emails admins and returns how many changed
+func (r *UserRepository) PromoteToAdmin(emails []string) (int64, error) {
+	if len(emails) == 0 {
+		return 0, nil
+	}
+	result := r.db.Model(&models.User{}).
+		Where("email IN ? AND email_verified_at IS NOT NULL AND role <> ?", emails, models.RoleAdmin).
+		Update("role", models.RoleAdmin)
+	return result.RowsAffected, result.Error
+}
+
 func (r *UserRepository) CreateUser(user *models.User) error {
 	return r.db.Create(user).Error
 }
@@ -64,6 +131,123 @@ func (r *UserRepository) UpdateUser(user *models.User) error {
 	return r.db.Save(user).Error
 }
 
+// UpdatePassword replaces only the password hash, leaving the rest of the row untouched
+func (r *UserRepository) UpdatePassword(userID uint, hash string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("password", hash).Error
+}
+
+// AdvanceTOTPStep records the last accepted TOTP time step. It reports false if that step
+// (or a later one) was already used, which stops a code from being replayed.
+func (r *UserRepository) AdvanceTOTPStep(userID uint, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteUser removes the user and everything in their personal library in one transaction.
+// Workspaces they are the last member of are deleted; in the others their resources and
+// collections are handed to a remaining owner. A user who is the only owner of a workspace with
+// other members cannot be deleted until someone else is made an owner.
 func (r *UserRepository) DeleteUser(userID uint) error {
-	return r.db.Unscoped().Delete(&models.User{}, userID).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var memberships []models.WorkspaceMember
+		if err := tx.Where("user_id = ? AND accepted_at IS NOT NULL", userID).Find(&memberships).Error; err != nil {
+			return err
+		}
+		for _, membership := range memberships {
+			if err := leaveWorkspace(tx, &membership); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+			return err
+		}
+
+		var resourceIDs []uint
+		if err := tx.Model(&models.Resource{}).Where("user_id = ? AND workspace_id IS NULL", userID).
+			Pluck("id", &resourceIDs).Error; err != nil {
+			return err
+		}
+		if err := deleteResources(tx, resourceIDs); err != nil {
+			return err
+		}
+
+		collectionIDs := tx.Model(&models.Collection{}).Select("id").Where("user_id = ? AND workspace_id IS NULL", userID)
+		if err := tx.Where("collection_id IN (?)", collectionIDs).Delete(&models.CollectionItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND workspace_id IS NULL", userID).Delete(&models.Collection{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.Folder{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.SavedQuery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.ResourceGrant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("from_user_id = ? OR to_user_id = ?", userID, userID).Delete(&models.ResourceTransfer{}).Error; err != nil {
+			return err
+		}
+
+		// Credentials and provider links go for good, so nothing can sign in as the deleted user
+		// and the provider subject is free to sign up again
+		for _, credential := range []interface{}{
+			&models.UserIdentity{},
+			&models.Session{},
+			&models.RefreshToken{},
+			&models.PersonalAccessToken{},
+			&models.OneTimeToken{},
+			&models.RecoveryCode{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(credential).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Where("link_user_id = ?", userID).Delete(&models.OIDCLoginState{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.DeviceAuthorization{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, userID).Error
+	})
+}
+
+// leaveWorkspace prepares a workspace for losing a member whose account is being deleted. The
+// workspace goes away with its last member; otherwise what the member created passes to the
+// longest-standing remaining owner.
+func leaveWorkspace(tx *gorm.DB, member *models.WorkspaceMember) error {
+	var owner models.WorkspaceMember
+	err := tx.Where("workspace_id = ? AND user_id <> ? AND role = ? AND accepted_at IS NOT NULL",
+		member.WorkspaceID, member.UserID, models.WorkspaceRoleOwner).
+		Order("accepted_at, id").First(&owner).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var others int64
+		if err := tx.Model(&models.WorkspaceMember{}).
+			Where("workspace_id = ? AND user_id <> ? AND accepted_at IS NOT NULL", member.WorkspaceID, member.UserID).
+			Count(&others).Error; err != nil {
+			return err
+		}
+		if others > 0 {
+			return models.ErrSoleWorkspaceOwner
+		}
+		return deleteWorkspace(tx, member.WorkspaceID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.Resource{}).Where("workspace_id = ? AND user_id = ?", member.WorkspaceID, member.UserID).
+		Update("user_id", owner.UserID).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Collection{}).Where("workspace_id = ? AND user_id = ?", member.WorkspaceID, member.UserID).
+		Update("user_id", owner.UserID).Error
 }
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"time"
+
+	"devlink/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type WorkspaceRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceRepository(db *gorm.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+// WorkspaceMembership is a workspace seen by one of its members
+type WorkspaceMembership struct {
+	models.Workspace
+	Role       models.WorkspaceRole
+	AcceptedAt *time.Time
+}
+
+// WorkspaceMemberDetail is a member together with their username
+type WorkspaceMemberDetail struct {
+	models.WorkspaceMember
+	Username string
+}
+
+func (r *WorkspaceRepository) GetByID(workspaceID uint) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.db.First(&workspace, workspaceID).Error; err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// CreateWorkspace creates the workspace with its creator as the first owner
+func (r *WorkspaceRepository) CreateWorkspace(workspace *models.Workspace) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		return tx.Create(&models.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      workspace.CreatedByID,
+			Role:        models.WorkspaceRoleOwner,
+			AcceptedAt:  &now,
+		}).Error
+	})
+}
+
+func (r *WorkspaceRepository) UpdateWorkspace(workspace *models.Workspace) error {
+	return r.db.Save(workspace).Error
+}
+
+// DeleteWorkspace removes the workspace, its members and its collections. Its resources go back
+// to the personal libraries of the members who created them, except links a creator already has
+// saved personally, which are deleted.
+func (r *WorkspaceRepository) DeleteWorkspace(workspaceID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteWorkspace(tx, workspaceID)
+	})
+}
+
+func deleteWorkspace(tx *gorm.DB, workspaceID uint) error {
+	var duplicateIDs []uint
+	if err := tx.Table("resources AS shared").
+		Joins("JOIN resources AS personal ON personal.canonical_link_id = shared.canonical_link_id "+
+			"AND personal.user_id = shared.user_id AND personal.workspace_id IS NULL AND personal.deleted_at IS NULL").
+		Where("shared.workspace_id = ? AND shared.deleted_at IS NULL", workspaceID).
+		Pluck("shared.id", &duplicateIDs).Error; err != nil {
+		return err
+	}
+	if err := deleteResources(tx, duplicateIDs); err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Resource{}).Where("workspace_id = ?", workspaceID).
+		Updates(map[string]interface{}{"workspace_id": nil, "folder_id": nil}).Error; err != nil {
+		return err
+	}
+	collectionIDs := tx.Model(&models.Collection{}).Select("id").Where("workspace_id = ?", workspaceID)
+	if err := tx.Where("collection_id IN (?)", collectionIDs).Delete(&models.CollectionItem{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.Collection{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("workspace_id = ?", workspaceID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.Workspace{}, workspaceID).Error
+}
+
+// GetMember returns the user's membership of the workspace, accepted or not
+func (r *WorkspaceRepository) GetMember(workspaceID, userID uint) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	if err := r.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMemberships returns a page of the workspaces the user belongs to, or has been invited to
+// when accepted is false, most recent first
+func (r *WorkspaceRepository) GetMemberships(userID uint, accepted bool, page, pageSize int) ([]WorkspaceMembership, int64, error) {
+	var memberships []WorkspaceMembership
+	var total int64
+
+	query := r.db.Model(&models.Workspace{}).
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID)
+	if accepted {
+		query = query.Where("workspace_members.accepted_at IS NOT NULL")
+	} else {
+		query = query.Where("workspace_members.accepted_at IS NULL")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	offset := (page - 1) * pageSize
+	err := query.Select("workspaces.*, workspace_members.role, workspace_members.accepted_at").
+		Order("workspace_members.id DESC").
+		Offset(offset).Limit(pageSize).
+		Scan(&memberships).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return memberships, total, nil
+}
+
+// GetMembers returns every member of the workspace, including pending invitations
+func (r *WorkspaceRepository) GetMembers(workspaceID uint) ([]WorkspaceMemberDetail, error) {
+	var members []WorkspaceMemberDetail
+	err := r.db.Model(&models.WorkspaceMember{}).
+		Select("workspace_members.*, users.username").
+		Joins("JOIN users ON users.id = workspace_members.user_id").
+		Where("workspace_members.workspace_id = ?", workspaceID).
+		Order("workspace_members.id").
+		Scan(&members).Error
+	return members, err
+}
+
+// AddMember records an invitation; it fails with ErrWorkspaceMemberExists if the user is already there
+func (r *WorkspaceRepository) AddMember(member *models.WorkspaceMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.WorkspaceMember{}).
+			Where("workspace_id = ? AND user_id = ?", member.WorkspaceID, member.UserID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return models.ErrWorkspaceMemberExists
+		}
+		return tx.Create(member).Error
+	})
+}
+
+func (r *WorkspaceRepository) AcceptInvitation(member *models.WorkspaceMember) error {
+	now := time.Now()
+	member.AcceptedAt = &now
+	return r.db.Model(member).Update("accepted_at", now).Error
+}
+
+// UpdateMemberRole changes a member's role. The last owner cannot be demoted.
+func (r *WorkspaceRepository) UpdateMemberRole(member *models.WorkspaceMember, role models.WorkspaceRole) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if member.Role == models.WorkspaceRoleOwner && role != models.WorkspaceRoleOwner {
+			if err := ensureAnotherOwner(tx, member); err != nil {
+				return err
+			}
+		}
+		return tx.Model(member).Update("role", role).Error
+	})
+	if err == nil {
+		member.Role = role
+	}
+	return err
+}
+
+// RemoveMember removes a member or cancels an invitation. The last owner cannot leave.
+func (r *WorkspaceRepository) RemoveMember(member *models.WorkspaceMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if member.Role == models.WorkspaceRoleOwner && member.IsActive() {
+			if err := ensureAnotherOwner(tx, member); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(member).Error
+	})
+}
+
+// ensureAnotherOwner fails unless the workspace has an active owner besides member
+func ensureAnotherOwner(tx *gorm.DB, member *models.WorkspaceMember) error {
+	var owners int64
+	err := tx.Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id <> ? AND role = ? AND accepted_at IS NOT NULL",
+			member.WorkspaceID, member.UserID, models.WorkspaceRoleOwner).
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return models.ErrLastWorkspaceOwner
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterAdminRoutes(router *mux.Router, auth *middleware.AuthMiddleware, adminHandler *handlers.AdminHandler) {
+	adminRouter := router.PathPrefix("/admin").Subrouter().StrictSlash(true)
+	adminRouter.Use(auth.JWTAuthMiddleware)
+
+	// Every admin route needs the admin scope on top of a role
+	moderator := func(next http.HandlerFunc) http.Handler {
+		return middleware.RequireRole(models.RoleModerator, middleware.RequireScope(models.ScopeAdmin, next))
+	}
+	admin := func(next http.HandlerFunc) http.Handler {
+		return middleware.RequireRole(models.RoleAdmin, middleware.RequireScope(models.ScopeAdmin, next))
+	}
+
+	// Moderation
+	adminRouter.Handle("/users", moderator(adminHandler.ListUsersHandler)).Methods("GET")
+	adminRouter.Handle("/users/{id:[0-9]+}", moderator(adminHandler.GetUserHandler)).Methods("GET")
+	adminRouter.Handle("/users/{id:[0-9]+}/suspend", moderator(adminHandler.SuspendUserHandler)).Methods("POST")
+	adminRouter.Handle("/users/{id:[0-9]+}/reactivate", moderator(adminHandler.ReactivateUserHandler)).Methods("POST")
+
+	// Account administration
+	adminRouter.Handle("/users/{id:[0-9]+}/role", admin(adminHandler.UpdateRoleHandler)).Methods("PUT")
+	adminRouter.Handle("/users/{id:[0-9]+}/password-reset", admin(adminHandler.ForcePasswordResetHandler)).Methods("POST")
+	adminRouter.Handle("/users/{id:[0-9]+}/unlock", admin(adminHandler.UnlockUserHandler)).Methods("POST")
+	adminRouter.Handle("/users/{id:[0-9]+}/audit", admin(adminHandler.GetAuditLogHandler)).Methods("GET")
+}
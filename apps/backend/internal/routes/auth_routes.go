@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"github.com/gorilla/mux"
+)
+
+func RegisterAuthRoutes(router *mux.Router, oidcHandler *handlers.OIDCHandler) {
+	authRouter := router.PathPrefix("/auth").Subrouter().StrictSlash(true)
+
+	// OpenID Connect sign-in; these are browser redirects, so they are not behind JWT auth
+	authRouter.HandleFunc("/oidc", oidcHandler.ListProvidersHandler).Methods("GET")
+	authRouter.HandleFunc("/oidc/{provider}/login", oidcHandler.LoginHandler).Methods("GET")
+	authRouter.HandleFunc("/oidc/{provider}/callback", oidcHandler.CallbackHandler).Methods("GET")
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterCollectionRoutes(router *mux.Router, auth *middleware.AuthMiddleware, collectionHandler *handlers.CollectionHandler) {
+	collectionRouter := router.PathPrefix("/collections").Subrouter().StrictSlash(true)
+
+	// Protected routes for authenticated users; collections hold resources so they share their scopes
+	collectionRouter.Use(auth.JWTAuthMiddleware)
+
+	// Collection CRUD routes
+	collectionRouter.Handle("", middleware.RequireScope(models.ScopeResourcesWrite, collectionHandler.CreateCollectionHandler)).Methods("POST")
+	collectionRouter.Handle("", middleware.RequireScope(models.ScopeResourcesRead, collectionHandler.GetCollectionsHandler)).Methods("GET")
+	collectionRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesRead, collectionHandler.GetCollectionHandler)).Methods("GET")
+	collectionRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, collectionHandler.UpdateCollectionHandler)).Methods("PUT")
+	collectionRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, collectionHandler.DeleteCollectionHandler)).Methods("DELETE")
+
+	// Membership and ordering routes
+	collectionRouter.Handle("/{id:[0-9]+}/resources", middleware.RequireScope(models.ScopeResourcesRead, collectionHandler.GetCollectionResourcesHandler)).Methods("GET")
+	collectionRouter.Handle("/{id:[0-9]+}/resources", middleware.RequireScope(models.ScopeResourcesWrite, collectionHandler.AddCollectionResourceHandler)).Methods("POST")
+	collectionRouter.Handle("/{id:[0-9]+}/resources/order", middleware.RequireScope(models.ScopeResourcesWrite, collectionHandler.ReorderCollectionHandler)).Methods("PUT")
+	collectionRouter.Handle("/{id:[0-9]+}/resources/{resourceId:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, collectionHandler.RemoveCollectionResourceHandler)).Methods("DELETE")
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterFolderRoutes(router *mux.Router, auth *middleware.AuthMiddleware, folderHandler *handlers.FolderHandler) {
+	folderRouter := router.PathPrefix("/folders").Subrouter().StrictSlash(true)
+
+	// Protected routes for authenticated users; folders organise resources so they share their scopes
+	folderRouter.Use(auth.JWTAuthMiddleware)
+
+	// Folder tree routes
+	folderRouter.Handle("", middleware.RequireScope(models.ScopeResourcesWrite, folderHandler.CreateFolderHandler)).Methods("POST")
+	folderRouter.Handle("", middleware.RequireScope(models.ScopeResourcesRead, folderHandler.GetFoldersHandler)).Methods("GET")
+	folderRouter.Handle("/lookup", middleware.RequireScope(models.ScopeResourcesRead, folderHandler.LookupFolderHandler)).Methods("GET")
+	folderRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesRead, folderHandler.GetFolderHandler)).Methods("GET")
+	folderRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, folderHandler.RenameFolderHandler)).Methods("PUT")
+	folderRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, folderHandler.DeleteFolderHandler)).Methods("DELETE")
+	folderRouter.Handle("/{id:[0-9]+}/move", middleware.RequireScope(models.ScopeResourcesWrite, folderHandler.MoveFolderHandler)).Methods("POST")
+}
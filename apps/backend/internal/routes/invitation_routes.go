@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterInvitationRoutes(router *mux.Router, auth *middleware.AuthMiddleware, invitationHandler *handlers.InvitationHandler) {
+	invitationRouter := router.PathPrefix("/invitations").Subrouter().StrictSlash(true)
+	invitationRouter.Use(auth.JWTAuthMiddleware)
+
+	// Creating invitations can be limited to moderators or admins with INVITATION_CREATOR_ROLE
+	creatorRole := handlers.InvitationCreatorRole()
+
+	invitationRouter.Handle("/", middleware.RequireScope(models.ScopeUsersRead, invitationHandler.GetInvitationsHandler)).Methods("GET")
+	invitationRouter.Handle("/", middleware.RequireRole(creatorRole, middleware.RequireScope(models.ScopeUsersWrite, invitationHandler.CreateInvitationHandler))).Methods("POST")
+	invitationRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeUsersRead, invitationHandler.GetInvitationHandler)).Methods("GET")
+	invitationRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeUsersWrite, invitationHandler.RevokeInvitationHandler)).Methods("DELETE")
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterOAuthRoutes(router *mux.Router, auth *middleware.AuthMiddleware, deviceHandler *handlers.DeviceHandler) {
+	oauthRouter := router.PathPrefix("/oauth").Subrouter().StrictSlash(true)
+
+	// Device authorization grant (RFC 8628); the device itself has no credentials yet
+	oauthRouter.HandleFunc("/device/code", deviceHandler.DeviceCodeHandler).Methods("POST")
+	oauthRouter.HandleFunc("/token", deviceHandler.TokenHandler).Methods("POST")
+
+	// The user approves the device from a browser where they are logged in
+	protected := oauthRouter.NewRoute().Subrouter()
+	protected.Use(auth.JWTAuthMiddleware)
+	protected.Handle("/device", middleware.RequireScope(models.ScopeUsersAdmin, deviceHandler.GetDeviceAuthorizationHandler)).Methods("GET")
+	protected.Handle("/device/approve", middleware.RequireScope(models.ScopeUsersAdmin, deviceHandler.ApproveDeviceHandler)).Methods("POST")
+	protected.Handle("/device/deny", middleware.RequireScope(models.ScopeUsersAdmin, deviceHandler.DenyDeviceHandler)).Methods("POST")
+}
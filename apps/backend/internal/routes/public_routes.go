@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterPublicRoutes serves resources to readers without an account
+func RegisterPublicRoutes(router *mux.Router, resourceHandler *handlers.ResourceHandler) {
+	publicRouter := router.PathPrefix("/public").Subrouter().StrictSlash(true)
+
+	publicRouter.HandleFunc("/resources", resourceHandler.GetPublicResourcesHandler).Methods("GET")
+	publicRouter.HandleFunc("/resources/{id:[0-9]+}", resourceHandler.GetPublicResourceHandler).Methods("GET")
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterResourceGrantRoutes(router *mux.Router, auth *middleware.AuthMiddleware, grantHandler *handlers.ResourceGrantHandler) {
+	// Resources other users shared with the caller
+	router.Handle("/resources/shared", auth.JWTAuthMiddleware(middleware.RequireScope(models.ScopeResourcesRead, grantHandler.GetSharedWithMeHandler))).Methods("GET")
+
+	grantRouter := router.PathPrefix("/resources/{id:[0-9]+}/grants").Subrouter().StrictSlash(true)
+
+	// Protected routes for the resource owner; grantees may only remove themselves
+	grantRouter.Use(auth.JWTAuthMiddleware)
+
+	grantRouter.Handle("", middleware.RequireScope(models.ScopeResourcesWrite, grantHandler.GrantResourceHandler)).Methods("POST")
+	grantRouter.Handle("", middleware.RequireScope(models.ScopeResourcesRead, grantHandler.GetResourceGrantsHandler)).Methods("GET")
+	grantRouter.Handle("/{userId:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, grantHandler.UpdateResourceGrantHandler)).Methods("PUT")
+	grantRouter.Handle("/{userId:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, grantHandler.RevokeResourceGrantHandler)).Methods("DELETE")
+}
@@ -3,23 +3,26 @@ package routes
 import (
 	"devlink/internal/handlers"
 	"devlink/internal/middleware"
+	"devlink/internal/models"
 	"github.com/gorilla/mux"
 )
 
-func RegisterResourceRoutes(router *mux.Router, resourceHandler *handlers.ResourceHandler) {
+func RegisterResourceRoutes(router *mux.Router, auth *middleware.AuthMiddleware, resourceHandler *handlers.ResourceHandler) {
 	resourceRouter := router.PathPrefix("/resources").Subrouter().StrictSlash(true)
 
-	// Protected routes for authenticated users
-	resourceRouter.Use(middleware.JWTAuthMiddleware)
+	// Protected routes for authenticated users; each declares the token scope it requires
+	resourceRouter.Use(auth.JWTAuthMiddleware)
 
-	// Resource CRUD routes
-	resourceRouter.HandleFunc("", resourceHandler.CreateResourceHandler).Methods("POST")
-	resourceRouter.HandleFunc("", resourceHandler.GetUserResourcesHandler).Methods("GET")
-	resourceRouter.HandleFunc("/{id}", resourceHandler.GetResourceByIDHandler).Methods("GET")
-	resourceRouter.HandleFunc("/{id}", resourceHandler.UpdateResourceHandler).Methods("PUT")
-	resourceRouter.HandleFunc("/{id}", resourceHandler.DeleteResourceHandler).Methods("DELETE")
+	// Search and filter routes come first so /{id} does not swallow them
+	resourceRouter.Handle("/search", middleware.RequireScope(models.ScopeResourcesRead, resourceHandler.SearchResourcesHandler)).Methods("GET")
+	resourceRouter.Handle("/tags", middleware.RequireScope(models.ScopeResourcesRead, resourceHandler.GetResourcesByTagsHandler)).Methods("GET")
+	resourceRouter.Handle("/lookup", middleware.RequireScope(models.ScopeResourcesRead, resourceHandler.LookupLinkHandler)).Methods("GET")
 
-	// Search and filter routes
-	resourceRouter.HandleFunc("/search", resourceHandler.SearchResourcesHandler).Methods("GET")
-	resourceRouter.HandleFunc("/tags", resourceHandler.GetResourcesByTagsHandler).Methods("GET")
-} 
\ No newline at end of file
+	// Resource CRUD routes
+	resourceRouter.Handle("", middleware.RequireScope(models.ScopeResourcesWrite, resourceHandler.CreateResourceHandler)).Methods("POST")
+	resourceRouter.Handle("", middleware.RequireScope(models.ScopeResourcesRead, resourceHandler.GetUserResourcesHandler)).Methods("GET")
+	resourceRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesRead, resourceHandler.GetResourceByIDHandler)).Methods("GET")
+	resourceRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, resourceHandler.UpdateResourceHandler)).Methods("PUT")
+	resourceRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, resourceHandler.DeleteResourceHandler)).Methods("DELETE")
+	resourceRouter.Handle("/{id:[0-9]+}/folder", middleware.RequireScope(models.ScopeResourcesWrite, resourceHandler.MoveResourceHandler)).Methods("PUT")
+}
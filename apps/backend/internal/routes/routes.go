@@ -9,7 +9,7 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func SetupRouter(h *handlers.HandlersContainer) *mux.Router {
+func SetupRouter(h *handlers.HandlersContainer, auth *middleware.AuthMiddleware) *mux.Router {
 	r := mux.NewRouter().StrictSlash(true)
 
 	// Create middleware instances
@@ -17,7 +17,7 @@ func SetupRouter(h *handlers.HandlersContainer) *mux.Router {
 	corsMiddleware := middleware.NewCORSMiddleware(
 		[]string{"http://localhost:5173", "http://127.0.0.1:5173"}, // Explicitly allowed frontend origins
 		[]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		[]string{"Content-Type", "Authorization"},
+		[]string{"Content-Type", "Authorization", middleware.AuthModeHeader, middleware.CSRFHeader},
 	)
 
 	// Apply global middlewares
@@ -36,11 +36,50 @@ func SetupRouter(h *handlers.HandlersContainer) *mux.Router {
 		w.Write([]byte("DevLink is running 🚀!"))
 	}).Methods("GET")
 
+	// Public keys for verifying access tokens
+	r.HandleFunc("/.well-known/jwks.json", handlers.JWKSHandler).Methods("GET")
+
 	// Register user routes
-	RegisterUserRoutes(r, h.UserHandler, h.AuthHandler)
+	RegisterUserRoutes(r, auth, h)
+
+	// Register external sign-in routes
+	RegisterAuthRoutes(r, h.OIDCHandler)
+
+	// Register OAuth device login routes
+	RegisterOAuthRoutes(r, auth, h.DeviceHandler)
+
+	// Register invitation routes
+	RegisterInvitationRoutes(r, auth, h.InvitationHandler)
+
+	// Register admin routes
+	RegisterAdminRoutes(r, auth, h.AdminHandler)
+
+	// Register share link routes before resource routes so /resources/{id}/shares is not shadowed
+	RegisterShareLinkRoutes(r, auth, h.ShareLinkHandler)
+
+	// Register per-user sharing routes, also ahead of the resource routes
+	RegisterResourceGrantRoutes(r, auth, h.ResourceGrantHandler)
 
 	// Register resource routes
-	RegisterResourceRoutes(r, h.ResourceHandler)
+	RegisterResourceRoutes(r, auth, h.ResourceHandler)
+
+	// Register unauthenticated read routes for public and unlisted resources
+	RegisterPublicRoutes(r, h.ResourceHandler)
+
+	// Register collection routes
+	RegisterCollectionRoutes(r, auth, h.CollectionHandler)
+
+	// Register folder routes
+	RegisterFolderRoutes(r, auth, h.FolderHandler)
+
+	// Register saved query routes
+	RegisterSavedQueryRoutes(r, auth, h.SavedQueryHandler)
+
+	// Register workspace routes
+	RegisterWorkspaceRoutes(r, auth, h.WorkspaceHandler)
+
+	// Register ownership transfer routes
+	RegisterTransferRoutes(r, auth, h.TransferHandler)
 
 	return r
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterSavedQueryRoutes(router *mux.Router, auth *middleware.AuthMiddleware, savedQueryHandler *handlers.SavedQueryHandler) {
+	savedQueryRouter := router.PathPrefix("/saved-queries").Subrouter().StrictSlash(true)
+
+	// Protected routes for authenticated users; saved queries search resources so they share their scopes
+	savedQueryRouter.Use(auth.JWTAuthMiddleware)
+
+	savedQueryRouter.Handle("", middleware.RequireScope(models.ScopeResourcesWrite, savedQueryHandler.CreateSavedQueryHandler)).Methods("POST")
+	savedQueryRouter.Handle("", middleware.RequireScope(models.ScopeResourcesRead, savedQueryHandler.GetSavedQueriesHandler)).Methods("GET")
+	savedQueryRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesRead, savedQueryHandler.GetSavedQueryHandler)).Methods("GET")
+	savedQueryRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, savedQueryHandler.UpdateSavedQueryHandler)).Methods("PUT")
+	savedQueryRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, savedQueryHandler.DeleteSavedQueryHandler)).Methods("DELETE")
+	savedQueryRouter.Handle("/{id:[0-9]+}/resources", middleware.RequireScope(models.ScopeResourcesRead, savedQueryHandler.GetSavedQueryResourcesHandler)).Methods("GET")
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterShareLinkRoutes(router *mux.Router, auth *middleware.AuthMiddleware, shareLinkHandler *handlers.ShareLinkHandler) {
+	// Public route: the token itself grants read access
+	router.HandleFunc("/share/{token}", shareLinkHandler.GetSharedResourceHandler).Methods("GET")
+
+	shareLinkRouter := router.PathPrefix("/resources/{id:[0-9]+}/shares").Subrouter().StrictSlash(true)
+
+	// Protected routes for the resource owner
+	shareLinkRouter.Use(auth.JWTAuthMiddleware)
+
+	shareLinkRouter.Handle("", middleware.RequireScope(models.ScopeResourcesWrite, shareLinkHandler.CreateShareLinkHandler)).Methods("POST")
+	shareLinkRouter.Handle("", middleware.RequireScope(models.ScopeResourcesRead, shareLinkHandler.GetShareLinksHandler)).Methods("GET")
+	shareLinkRouter.Handle("/{linkId:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, shareLinkHandler.RevokeShareLinkHandler)).Methods("DELETE")
+}
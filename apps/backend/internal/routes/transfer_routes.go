@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterTransferRoutes(router *mux.Router, auth *middleware.AuthMiddleware, transferHandler *handlers.TransferHandler) {
+	transferRouter := router.PathPrefix("/transfers").Subrouter().StrictSlash(true)
+
+	// Protected routes for authenticated users; transfers move resources so they share their scopes
+	transferRouter.Use(auth.JWTAuthMiddleware)
+
+	transferRouter.Handle("", middleware.RequireScope(models.ScopeResourcesWrite, transferHandler.CreateTransferHandler)).Methods("POST")
+	transferRouter.Handle("", middleware.RequireScope(models.ScopeResourcesRead, transferHandler.GetTransfersHandler)).Methods("GET")
+	transferRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesRead, transferHandler.GetTransferHandler)).Methods("GET")
+	transferRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, transferHandler.CancelTransferHandler)).Methods("DELETE")
+	transferRouter.Handle("/{id:[0-9]+}/accept", middleware.RequireScope(models.ScopeResourcesWrite, transferHandler.AcceptTransferHandler)).Methods("POST")
+	transferRouter.Handle("/{id:[0-9]+}/decline", middleware.RequireScope(models.ScopeResourcesWrite, transferHandler.DeclineTransferHandler)).Methods("POST")
+}
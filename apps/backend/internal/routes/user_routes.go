@@ -3,23 +3,60 @@ package routes
 import (
 	"devlink/internal/handlers"
 	"devlink/internal/middleware"
+	"devlink/internal/models"
 	"github.com/gorilla/mux"
 )
 
-func RegisterUserRoutes(router *mux.Router, userHandler *handlers.UserHandler, authHandler *handlers.AuthHandler) {
+func RegisterUserRoutes(router *mux.Router, auth *middleware.AuthMiddleware, h *handlers.HandlersContainer) {
 	userRouter := router.PathPrefix("/users").Subrouter().StrictSlash(true)
 
 	// Auth-related routes
-	userRouter.HandleFunc("/register", authHandler.RegisterUserHandler).Methods("POST")
-	userRouter.HandleFunc("/login", authHandler.LoginUserHandler).Methods("POST")
-	userRouter.HandleFunc("/logout", authHandler.LogoutUserHandler).Methods("POST")
-	
-	// Protected routes for authenticated users
+	userRouter.HandleFunc("/register", h.AuthHandler.RegisterUserHandler).Methods("POST")
+	userRouter.HandleFunc("/register/challenge", h.AuthHandler.RegistrationChallengeHandler).Methods("GET")
+	userRouter.HandleFunc("/login", h.AuthHandler.LoginUserHandler).Methods("POST")
+	userRouter.HandleFunc("/login/mfa", h.AuthHandler.LoginMFAHandler).Methods("POST")
+	userRouter.HandleFunc("/login/magic", h.MagicLinkHandler.RequestMagicLinkHandler).Methods("POST")
+	userRouter.HandleFunc("/login/magic/verify", h.MagicLinkHandler.MagicLinkLoginHandler).Methods("POST")
+	userRouter.HandleFunc("/refresh", h.AuthHandler.RefreshTokenHandler).Methods("POST")
+	userRouter.HandleFunc("/unlock", h.AuthHandler.UnlockAccountHandler).Methods("POST")
+
+	// Password recovery routes
+	userRouter.HandleFunc("/password/forgot", h.PasswordHandler.ForgotPasswordHandler).Methods("POST")
+	userRouter.HandleFunc("/password/reset", h.PasswordHandler.ResetPasswordHandler).Methods("POST")
+
+	// Email verification routes
+	userRouter.HandleFunc("/email/verify", h.VerificationHandler.VerifyEmailHandler).Methods("POST")
+
+	// Protected routes for authenticated users; each declares the token scope it requires
 	protected := userRouter.NewRoute().Subrouter()
-	// User-related routes
-	protected.Use(middleware.JWTAuthMiddleware)
-	protected.HandleFunc("/", userHandler.GetAllUsersHandler).Methods("GET")
-	protected.HandleFunc("/{id}", userHandler.GetUserByIDHandler).Methods("GET")
-	protected.HandleFunc("/{id}", userHandler.UpdateUserHandler).Methods("PUT")
-	protected.HandleFunc("/{id}", userHandler.DeleteUserHandler).Methods("DELETE")
+	protected.Use(auth.JWTAuthMiddleware)
+	protected.Handle("/logout", middleware.RequireScope(models.ScopeUsersWrite, h.AuthHandler.LogoutUserHandler)).Methods("POST")
+	protected.Handle("/email/resend", middleware.RequireScope(models.ScopeUsersWrite, h.VerificationHandler.ResendVerificationHandler)).Methods("POST")
+
+	// Personal access token routes
+	protected.Handle("/me/tokens", middleware.RequireScope(models.ScopeUsersAdmin, h.TokenHandler.GetUserTokensHandler)).Methods("GET")
+	protected.Handle("/me/tokens", middleware.RequireScope(models.ScopeUsersAdmin, h.TokenHandler.CreateTokenHandler)).Methods("POST")
+	protected.Handle("/me/tokens/{id}", middleware.RequireScope(models.ScopeUsersAdmin, h.TokenHandler.RevokeTokenHandler)).Methods("DELETE")
+
+	// Session routes
+	protected.Handle("/me/sessions", middleware.RequireScope(models.ScopeUsersAdmin, h.SessionHandler.GetSessionsHandler)).Methods("GET")
+	protected.Handle("/me/sessions", middleware.RequireScope(models.ScopeUsersAdmin, h.SessionHandler.RevokeAllSessionsHandler)).Methods("DELETE")
+	protected.Handle("/me/sessions/{id:[0-9]+}", middleware.RequireScope(models.ScopeUsersAdmin, h.SessionHandler.RevokeSessionHandler)).Methods("DELETE")
+
+	// Two-factor authentication routes
+	protected.Handle("/me/mfa/totp", middleware.RequireScope(models.ScopeUsersAdmin, h.MFAHandler.EnrollTOTPHandler)).Methods("POST")
+	protected.Handle("/me/mfa/totp", middleware.RequireScope(models.ScopeUsersAdmin, h.MFAHandler.DisableTOTPHandler)).Methods("DELETE")
+	protected.Handle("/me/mfa/totp/confirm", middleware.RequireScope(models.ScopeUsersAdmin, h.MFAHandler.ConfirmTOTPHandler)).Methods("POST")
+	protected.Handle("/me/mfa/recovery-codes", middleware.RequireScope(models.ScopeUsersAdmin, h.MFAHandler.RegenerateRecoveryCodesHandler)).Methods("POST")
+
+	// Linked identity provider routes
+	protected.Handle("/me/identities", middleware.RequireScope(models.ScopeUsersAdmin, h.OIDCHandler.GetIdentitiesHandler)).Methods("GET")
+	protected.Handle("/me/identities/{provider}", middleware.RequireScope(models.ScopeUsersAdmin, h.OIDCHandler.LinkIdentityHandler)).Methods("POST")
+	protected.Handle("/me/identities/{id:[0-9]+}", middleware.RequireScope(models.ScopeUsersAdmin, h.OIDCHandler.UnlinkIdentityHandler)).Methods("DELETE")
+
+	// User-related routes; listing everyone exposes emails, so it is for moderators only
+	protected.Handle("/", middleware.RequireRole(models.RoleModerator, middleware.RequireScope(models.ScopeUsersRead, h.UserHandler.GetAllUsersHandler))).Methods("GET")
+	protected.Handle("/{id}", middleware.RequireScope(models.ScopeUsersRead, h.UserHandler.GetUserByIDHandler)).Methods("GET")
+	protected.Handle("/{id}", middleware.RequireScope(models.ScopeUsersWrite, h.UserHandler.UpdateUserHandler)).Methods("PUT")
+	protected.Handle("/{id}", middleware.RequireScope(models.ScopeUsersWrite, h.UserHandler.DeleteUserHandler)).Methods("DELETE")
 }
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"devlink/internal/handlers"
+	"devlink/internal/middleware"
+	"devlink/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func RegisterWorkspaceRoutes(router *mux.Router, auth *middleware.AuthMiddleware, workspaceHandler *handlers.WorkspaceHandler) {
+	workspaceRouter := router.PathPrefix("/workspaces").Subrouter().StrictSlash(true)
+
+	// Protected routes for authenticated users; workspaces hold resources so they share their scopes
+	workspaceRouter.Use(auth.JWTAuthMiddleware)
+
+	workspaceRouter.Handle("", middleware.RequireScope(models.ScopeResourcesWrite, workspaceHandler.CreateWorkspaceHandler)).Methods("POST")
+	workspaceRouter.Handle("", middleware.RequireScope(models.ScopeResourcesRead, workspaceHandler.GetWorkspacesHandler)).Methods("GET")
+	workspaceRouter.Handle("/invitations", middleware.RequireScope(models.ScopeResourcesRead, workspaceHandler.GetWorkspaceInvitationsHandler)).Methods("GET")
+	workspaceRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesRead, workspaceHandler.GetWorkspaceHandler)).Methods("GET")
+	workspaceRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, workspaceHandler.UpdateWorkspaceHandler)).Methods("PUT")
+	workspaceRouter.Handle("/{id:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, workspaceHandler.DeleteWorkspaceHandler)).Methods("DELETE")
+
+	// Membership of the caller
+	workspaceRouter.Handle("/{id:[0-9]+}/accept", middleware.RequireScope(models.ScopeResourcesWrite, workspaceHandler.AcceptWorkspaceInvitationHandler)).Methods("POST")
+	workspaceRouter.Handle("/{id:[0-9]+}/membership", middleware.RequireScope(models.ScopeResourcesWrite, workspaceHandler.LeaveWorkspaceHandler)).Methods("DELETE")
+
+	// Member management
+	workspaceRouter.Handle("/{id:[0-9]+}/members", middleware.RequireScope(models.ScopeResourcesRead, workspaceHandler.GetWorkspaceMembersHandler)).Methods("GET")
+	workspaceRouter.Handle("/{id:[0-9]+}/members", middleware.RequireScope(models.ScopeResourcesWrite, workspaceHandler.InviteWorkspaceMemberHandler)).Methods("POST")
+	workspaceRouter.Handle("/{id:[0-9]+}/members/{userId:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, workspaceHandler.UpdateWorkspaceMemberHandler)).Methods("PUT")
+	workspaceRouter.Handle("/{id:[0-9]+}/members/{userId:[0-9]+}", middleware.RequireScope(models.ScopeResourcesWrite, workspaceHandler.RemoveWorkspaceMemberHandler)).Methods("DELETE")
+}
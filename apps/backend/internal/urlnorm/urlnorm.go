@@ -0,0 +1,211 @@
+// Package urlnorm normalizes the URLs users save so that trivially different spellings of the
+// same page are stored the same way. Normalize gives the form that is stored and shown; Canonical
+// goes further and gives the form used to recognise the same page across resources.
+package urlnorm
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+
+	"devlink/internal/config"
+)
+
+// DefaultTrackingParams are stripped unless URL_TRACKING_PARAMS says otherwise. A trailing *
+// matches any parameter with that prefix.
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "msclkid", "yclid", "igshid", "mc_cid", "mc_eid", "_ga",
+}
+
+var ErrInvalidURL = errors.New("not an absolute URL")
+
+// defaultPorts are dropped from hosts because they say nothing the scheme does not
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalizer applies the configured normalization steps
+type Normalizer struct {
+	trackingParams   map[string]bool
+	trackingPrefixes []string
+	stripFragment    bool
+}
+
+// New builds a normalizer that strips the given tracking parameters, matched case-insensitively,
+// and optionally drops fragments
+func New(trackingParams []string, stripFragment bool) *Normalizer {
+	n := &Normalizer{trackingParams: map[string]bool{}, stripFragment: stripFragment}
+	for _, param := range trackingParams {
+		param = strings.ToLower(strings.TrimSpace(param))
+		switch {
+		case param == "":
+		case strings.HasSuffix(param, "*"):
+			n.trackingPrefixes = append(n.trackingPrefixes, strings.TrimSuffix(param, "*"))
+		default:
+			n.trackingParams[param] = true
+		}
+	}
+	return n
+}
+
+// NewFromEnv reads URL_TRACKING_PARAMS (comma-separated, DefaultTrackingParams when unset) and
+// URL_STRIP_FRAGMENT
+func NewFromEnv() *Normalizer {
+	params := config.GetEnvList("URL_TRACKING_PARAMS")
+	if len(params) == 0 {
+		params = DefaultTrackingParams
+	}
+	return New(params, config.GetEnvBool("URL_STRIP_FRAGMENT", false))
+}
+
+// Normalize lowercases the scheme and host, drops default ports, gives an empty path its "/",
+// removes tracking parameters, decodes percent-escapes of characters that never need them and
+// uppercases the rest, and drops the fragment if configured to. The query keeps its order.
+func (n *Normalizer) Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", ErrInvalidURL
+	}
+
+	var b strings.Builder
+	scheme := strings.ToLower(u.Scheme)
+	b.WriteString(scheme)
+	b.WriteString("://")
+	if u.User != nil {
+		b.WriteString(u.User.String())
+		b.WriteByte('@')
+	}
+	host := strings.ToLower(u.Host)
+	if port := u.Port(); port != "" && port == defaultPorts[scheme] {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	b.WriteString(host)
+
+	path := normalizeEscapes(u.EscapedPath())
+	if path == "" {
+		path = "/"
+	}
+	b.WriteString(path)
+
+	if query := n.queryParams(u.RawQuery); len(query) > 0 {
+		b.WriteByte('?')
+		b.WriteString(strings.Join(query, "&"))
+	}
+
+	if u.Fragment != "" && !n.stripFragment {
+		b.WriteByte('#')
+		b.WriteString(normalizeEscapes(u.EscapedFragment()))
+	}
+	return b.String(), nil
+}
+
+// Canonical normalizes the URL and also sorts its query and drops a trailing slash from the
+// path, so that URLs which almost always mean the same page compare equal
+func (n *Normalizer) Canonical(raw string) (string, error) {
+	normalized, err := n.Normalize(raw)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		sort.Strings(params)
+		u.RawQuery = strings.Join(params, "&")
+	}
+	if path := u.EscapedPath(); len(path) > 1 && strings.HasSuffix(path, "/") {
+		trimmed := strings.TrimRight(path, "/")
+		if trimmed == "" {
+			trimmed = "/"
+		}
+		if unescaped, err := url.PathUnescape(trimmed); err == nil {
+			u.Path = unescaped
+			u.RawPath = trimmed
+		}
+	}
+	return u.String(), nil
+}
+
+// queryParams returns the query's parameters in order without the tracking ones
+func (n *Normalizer) queryParams(rawQuery string) []string {
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		key := param
+		if i := strings.IndexByte(param, '='); i >= 0 {
+			key = param[:i]
+		}
+		if decoded, err := url.QueryUnescape(key); err == nil {
+			key = decoded
+		}
+		if n.isTracking(strings.ToLower(key)) {
+			continue
+		}
+		params = append(params, normalizeEscapes(param))
+	}
+	return params
+}
+
+func (n *Normalizer) isTracking(key string) bool {
+	if n.trackingParams[key] {
+		return true
+	}
+	for _, prefix := range n.trackingPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEscapes decodes percent-escapes of unreserved characters (RFC 3986 section 2.3) and
+// uppercases the hex digits of the others. Malformed escapes are left alone.
+func normalizeEscapes(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
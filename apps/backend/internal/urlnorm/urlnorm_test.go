@@ -0,0 +1,93 @@
+package urlnorm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		raw           string
+		stripFragment bool
+		want          string
+	}{
+		{name: "scheme and host case", raw: "HTTPS://Example.COM/Path", want: "https://example.com/Path"},
+		{name: "surrounding space", raw: "  https://example.com/  ", want: "https://example.com/"},
+		{name: "default http port", raw: "http://example.com:80/a", want: "http://example.com/a"},
+		{name: "default https port", raw: "https://example.com:443/a", want: "https://example.com/a"},
+		{name: "other port kept", raw: "https://example.com:8443/a", want: "https://example.com:8443/a"},
+		{name: "http port on https kept", raw: "https://example.com:80/a", want: "https://example.com:80/a"},
+		{name: "empty path", raw: "https://example.com", want: "https://example.com/"},
+		{name: "empty path with query", raw: "https://example.com?q=1", want: "https://example.com/?q=1"},
+		{name: "utm parameters", raw: "https://example.com/a?utm_source=x&id=1&UTM_Medium=y", want: "https://example.com/a?id=1"},
+		{name: "exact tracking parameters", raw: "https://example.com/a?fbclid=1&gclid=2&page=3", want: "https://example.com/a?page=3"},
+		{name: "escaped tracking parameter", raw: "https://example.com/a?utm%5Fsource=x&b=1", want: "https://example.com/a?b=1"},
+		{name: "only tracking parameters", raw: "https://example.com/a?utm_source=x", want: "https://example.com/a"},
+		{name: "query order kept", raw: "https://example.com/a?b=2&a=1", want: "https://example.com/a?b=2&a=1"},
+		{name: "unreserved escapes decoded", raw: "https://example.com/%7Euser/%41bc", want: "https://example.com/~user/Abc"},
+		{name: "reserved escapes uppercased", raw: "https://example.com/a%2fb?q=a%2bb", want: "https://example.com/a%2Fb?q=a%2Bb"},
+		{name: "fragment kept", raw: "https://example.com/a#Section", want: "https://example.com/a#Section"},
+		{name: "fragment stripped", raw: "https://example.com/a#Section", stripFragment: true, want: "https://example.com/a"},
+		{name: "user info kept", raw: "https://me@Example.com/a", want: "https://me@example.com/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(DefaultTrackingParams, tt.stripFragment).Normalize(tt.raw)
+			if err != nil {
+				t.Fatalf("Normalize(%q): %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeRejectsRelativeURLs(t *testing.T) {
+	for _, raw := range []string{"", "example.com/a", "/a/b", "https://", "http://%zz"} {
+		if _, err := New(nil, false).Normalize(raw); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("Normalize(%q): got %v, want ErrInvalidURL", raw, err)
+		}
+	}
+}
+
+func TestNormalizeConfiguredTrackingParams(t *testing.T) {
+	n := New([]string{" Ref ", "ga_*", ""}, false)
+	got, err := n.Normalize("https://example.com/a?ref=1&ga_id=2&utm_source=3&x=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://example.com/a?utm_source=3&x=4"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "query sorted", raw: "https://example.com/a?b=2&a=1", want: "https://example.com/a?a=1&b=2"},
+		{name: "trailing slash trimmed", raw: "https://example.com/docs/", want: "https://example.com/docs"},
+		{name: "repeated trailing slashes trimmed", raw: "https://example.com/docs//", want: "https://example.com/docs"},
+		{name: "root slash kept", raw: "https://Example.com", want: "https://example.com/"},
+		{name: "escapes survive trimming", raw: "https://example.com/a%2Fb/", want: "https://example.com/a%2Fb"},
+		{name: "normalized first", raw: "HTTPS://Example.com:443/docs/?utm_source=x&z=1&a=2#top", want: "https://example.com/docs?a=2&z=1#top"},
+	}
+
+	n := New(DefaultTrackingParams, false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := n.Canonical(tt.raw)
+			if err != nil {
+				t.Fatalf("Canonical(%q): %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Canonical(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,26 +1,79 @@
 package utils
 
 import (
-	"time" 
+	"time"
+
 	"devlink/internal/config"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte(config.GetEnv("JWT_SECRET", "secret")) 
+// Token types carried in the "typ" claim so a token issued for one purpose cannot be used for another
+const (
+	TokenTypeAccess       = "access"
+	TokenTypeMFAChallenge = "mfa_pending"
+)
+
+// AccessTokenTTL is how long an access token stays valid
+func AccessTokenTTL() time.Duration {
+	return config.GetEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+// RefreshTokenTTL is how long a refresh token can be exchanged before the user must log in again
+func RefreshTokenTTL() time.Duration {
+	return config.GetEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
 
-// GetJWTSecret returns the JWT secret key
-func GetJWTSecret() []byte {
-	return jwtSecret
+// MFAChallengeTTL is how long a user has to enter their second factor after a correct password
+func MFAChallengeTTL() time.Duration {
+	return config.GetEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute)
 }
 
-func GenerateJWT(userID uint, email string, usename string) (string, error) {
-	claims := jwt.MapClaims{
+// GenerateJWT issues a short-lived access token. familyID ties the token to the
+// refresh-token chain it was issued with so logout can revoke both.
+func GenerateJWT(userID uint, email string, username string, familyID string) (string, error) {
+	return signToken(TokenTypeAccess, AccessTokenTTL(), jwt.MapClaims{
 		"user_id":  userID,
 		"email":    email,
-		"username": usename,
-		"exp":      time.Now().Add(time.Hour * 72).Unix(), // 3 days expiry
+		"username": username,
+		"fid":      familyID,
+	})
+}
+
+// GenerateMFAChallenge issues a short-lived token proving the password step succeeded.
+// It can only be exchanged for an access token together with a valid second factor.
+func GenerateMFAChallenge(userID uint) (string, error) {
+	return signToken(TokenTypeMFAChallenge, MFAChallengeTTL(), jwt.MapClaims{
+		"user_id": userID,
+	})
+}
+
+func signToken(tokenType string, ttl time.Duration, claims jwt.MapClaims) (string, error) {
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims["typ"] = tokenType
+	claims["jti"] = jti
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	return ActiveKeyring().Sign(claims)
+}
+
+// ParseJWT validates a token of the given type and returns its claims
+func ParseJWT(tokenString string, tokenType string) (jwt.MapClaims, error) {
+	keyring := ActiveKeyring()
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyring.Keyfunc, jwt.WithValidMethods(keyring.ValidMethods()))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		return nil, jwt.ErrTokenInvalidClaims
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return claims, nil
 }
@@ -0,0 +1,295 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"devlink/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// defaultJWTSecret is the development fallback that production deployments must override
+const defaultJWTSecret = "secret"
+
+// minProductionSecretLength is the shortest HS256 secret accepted in production (256 bits)
+const minProductionSecretLength = 32
+
+// publicJWTSecrets are values published in docs and examples, as good as no secret at all
+var publicJWTSecrets = map[string]bool{
+	defaultJWTSecret:  true,
+	"your_jwt_secret": true,
+	"changeme":        true,
+}
+
+var (
+	ErrInsecureJWTConfig  = errors.New("refusing to start in production with a missing, default or short JWT_SECRET: set JWT_KEYS_DIR or a random JWT_SECRET of at least 32 characters")
+	ErrInvalidLegacyHS256 = errors.New("JWT_LEGACY_HS256_UNTIL must be an RFC 3339 time or a date like 2006-01-02")
+)
+
+// SigningKey is one entry of the keyring, identified by its kid
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+}
+
+// Keyring holds every key DevLink accepts tokens from and the one it currently signs with.
+// New keys are added by dropping a PEM file into JWT_KEYS_DIR; the newest becomes active while
+// older keys keep verifying until they are removed.
+type Keyring struct {
+	mu           sync.RWMutex
+	dir          string
+	keys         map[string]*SigningKey
+	active       *SigningKey
+	legacySecret []byte
+	// legacyUntil keeps HS256 tokens verifying for a while after asymmetric keys are introduced
+	legacyUntil time.Time
+}
+
+var (
+	keyringMu     sync.RWMutex
+	activeKeyring = &Keyring{keys: map[string]*SigningKey{}, legacySecret: []byte(defaultJWTSecret)}
+)
+
+// SetKeyring installs the keyring used to sign and verify tokens
+func SetKeyring(k *Keyring) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	activeKeyring = k
+}
+
+// ActiveKeyring returns the keyring used to sign and verify tokens
+func ActiveKeyring() *Keyring {
+	keyringMu.RLock()
+	defer keyringMu.RUnlock()
+	return activeKeyring
+}
+
+// LoadKeyringFromEnv builds the keyring from JWT_KEYS_DIR, JWT_ACTIVE_KID, JWT_SECRET and
+// JWT_LEGACY_HS256_UNTIL. Without asymmetric keys it falls back to HS256 with JWT_SECRET. Once a
+// key is active HS256 tokens are rejected, unless JWT_LEGACY_HS256_UNTIL keeps them verifying
+// for a migration window. In production the HS256 secret must be strong whenever it is used.
+func LoadKeyringFromEnv() (*Keyring, error) {
+	k := &Keyring{dir: config.GetEnv("JWT_KEYS_DIR", "")}
+	if secret := config.GetEnv("JWT_SECRET", ""); secret != "" {
+		k.legacySecret = []byte(secret)
+	}
+	if until := config.GetEnv("JWT_LEGACY_HS256_UNTIL", ""); until != "" {
+		parsed, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			if parsed, err = time.Parse("2006-01-02", until); err != nil {
+				return nil, ErrInvalidLegacyHS256
+			}
+		}
+		k.legacyUntil = parsed
+	}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+
+	if k.active == nil && len(k.legacySecret) == 0 {
+		k.legacySecret = []byte(defaultJWTSecret)
+	}
+	production := strings.EqualFold(config.GetEnv("APP_ENV", "development"), "production")
+	usesSecret := k.active == nil || time.Now().Before(k.legacyUntil)
+	if production && usesSecret && !strongSecret(k.legacySecret) {
+		return nil, ErrInsecureJWTConfig
+	}
+	return k, nil
+}
+
+func strongSecret(secret []byte) bool {
+	return len(secret) >= minProductionSecretLength && !publicJWTSecrets[string(secret)]
+}
+
+// acceptsHS256 reports whether HS256 tokens verify: always while there is no asymmetric key,
+// and only inside the legacy window once there is. Callers hold k.mu.
+func (k *Keyring) acceptsHS256() bool {
+	if len(k.legacySecret) == 0 {
+		return false
+	}
+	return k.active == nil || time.Now().Before(k.legacyUntil)
+}
+
+// Reload re-reads the key directory so a rotated key is picked up without a restart
+func (k *Keyring) Reload() error {
+	keys := make(map[string]*SigningKey)
+	if k.dir != "" {
+		paths, err := filepath.Glob(filepath.Join(k.dir, "*.pem"))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			key, err := loadSigningKey(path)
+			if err != nil {
+				return fmt.Errorf("loading JWT key %s: %w", path, err)
+			}
+			keys[key.ID] = key
+		}
+	}
+
+	var active *SigningKey
+	if len(keys) > 0 {
+		kid := config.GetEnv("JWT_ACTIVE_KID", "")
+		if kid == "" {
+			// Key files are named by creation time, so the last one is the newest
+			ids := make([]string, 0, len(keys))
+			for id := range keys {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			kid = ids[len(ids)-1]
+		}
+		var ok bool
+		if active, ok = keys[kid]; !ok {
+			return fmt.Errorf("JWT_ACTIVE_KID %q not found in %s", kid, k.dir)
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys
+	k.active = active
+	return nil
+}
+
+// Sign signs claims with the active key, tagging the token with its kid
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(k.legacySecret)
+	}
+	token := jwt.NewWithClaims(k.active.Method, claims)
+	token.Header["kid"] = k.active.ID
+	return token.SignedString(k.active.PrivateKey)
+}
+
+// Keyfunc selects the verification key for a token. HS256 is only accepted while the shared
+// secret is in use, so an RSA public key can never be used as an HMAC secret.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if token.Method == jwt.SigningMethodHS256 {
+		if !k.acceptsHS256() {
+			return nil, jwt.ErrTokenUnverifiable
+		}
+		return k.legacySecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok || key.Method.Alg() != token.Method.Alg() {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	return key.PrivateKey.Public(), nil
+}
+
+// ValidMethods lists the algorithms the keyring can verify
+func (k *Keyring) ValidMethods() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	var methods []string
+	if k.acceptsHS256() {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	seen := map[string]bool{}
+	for _, key := range k.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// JSONWebKey is a public key in JWKS format (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS returns the public half of every asymmetric key so other services can verify DevLink tokens
+func (k *Keyring) JWKS() []JSONWebKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := make([]JSONWebKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		jwk := JSONWebKey{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.PrivateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		keys = append(keys, jwk)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Kid < keys[j].Kid })
+	return keys
+}
+
+// loadSigningKey reads a PEM private key; the file name without extension is the kid
+func loadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, PrivateKey: key}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, PrivateKey: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+}
@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newKeyDir points JWT_KEYS_DIR at an empty directory and clears the other keyring settings
+func newKeyDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_ACTIVE_KID", "")
+	t.Setenv("JWT_SECRET", "legacy-secret-legacy-secret-legacy")
+	t.Setenv("JWT_LEGACY_HS256_UNTIL", "")
+	t.Setenv("APP_ENV", "development")
+	return dir
+}
+
+// writeKey stores key in dir as jwtkeygen does, named after its kid
+func writeKey(t *testing.T, dir, kid string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func loadKeyring(t *testing.T) *Keyring {
+	t.Helper()
+	k, err := LoadKeyringFromEnv()
+	if err != nil {
+		t.Fatalf("LoadKeyringFromEnv: %v", err)
+	}
+	return k
+}
+
+// useKeyring installs k for ParseJWT and friends until the test ends
+func useKeyring(t *testing.T, k *Keyring) {
+	t.Helper()
+	previous := ActiveKeyring()
+	SetKeyring(k)
+	t.Cleanup(func() { SetKeyring(previous) })
+}
+
+// verify parses a token the way ParseJWT does
+func verify(k *Keyring, token string) error {
+	_, err := jwt.Parse(token, k.Keyfunc, jwt.WithValidMethods(k.ValidMethods()))
+	return err
+}
+
+func testTokenClaims() jwt.MapClaims {
+	return jwt.MapClaims{"user_id": 1, "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func TestKeyringSignsWithActiveKey(t *testing.T) {
+	dir := newKeyDir(t)
+	writeKey(t, dir, "20240101T000000Z", newEd25519Key(t))
+	writeKey(t, dir, "20250101T000000Z", newEd25519Key(t))
+	k := loadKeyring(t)
+	useKeyring(t, k)
+
+	token, err := GenerateJWT(1, "user@example.com", "user", "family")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := parsed.Header["kid"]; kid != "20250101T000000Z" {
+		t.Fatalf("signed with kid %v, want the newest key", kid)
+	}
+	if parsed.Method != jwt.SigningMethodEdDSA {
+		t.Fatalf("signed with %s, want EdDSA", parsed.Method.Alg())
+	}
+	if _, err := ParseJWT(token, TokenTypeAccess); err != nil {
+		t.Fatalf("ParseJWT: %v", err)
+	}
+
+	// JWT_ACTIVE_KID pins an older key
+	t.Setenv("JWT_ACTIVE_KID", "20240101T000000Z")
+	if err := k.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	token, err = k.Sign(testTokenClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, _, _ = jwt.NewParser().ParseUnverified(token, jwt.MapClaims{}); parsed.Header["kid"] != "20240101T000000Z" {
+		t.Fatalf("signed with kid %v, want the pinned key", parsed.Header["kid"])
+	}
+
+	t.Setenv("JWT_ACTIVE_KID", "missing")
+	if err := k.Reload(); err == nil {
+		t.Fatal("Reload accepted a JWT_ACTIVE_KID that is not in the directory")
+	}
+}
+
+func TestKeyringVerifiesAfterRotation(t *testing.T) {
+	dir := newKeyDir(t)
+	writeKey(t, dir, "old", newEd25519Key(t))
+	k := loadKeyring(t)
+
+	oldToken, err := k.Sign(testTokenClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate: an RSA key that sorts after the old one becomes active on reload
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "zz-new", rsaKey)
+	if err := k.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := k.Sign(testTokenClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verify(k, oldToken); err != nil {
+		t.Fatalf("token from the previous key: %v", err)
+	}
+	if err := verify(k, newToken); err != nil {
+		t.Fatalf("token from the new key: %v", err)
+	}
+
+	// Once the old key file is removed its tokens stop verifying
+	if err := os.Remove(filepath.Join(dir, "old.pem")); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(k, oldToken); err == nil {
+		t.Fatal("token from a removed key still verifies")
+	}
+	if err := verify(k, newToken); err != nil {
+		t.Fatalf("token from the new key after removing the old one: %v", err)
+	}
+}
+
+func TestKeyringRejectsUnverifiableTokens(t *testing.T) {
+	dir := newKeyDir(t)
+	edKey := newEd25519Key(t)
+	writeKey(t, dir, "k1", edKey)
+	k := loadKeyring(t)
+
+	signed := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, testTokenClaims())
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "unknown kid", token: signed(jwt.SigningMethodEdDSA, "k2", newEd25519Key(t))},
+		{name: "missing kid", token: signed(jwt.SigningMethodEdDSA, "", edKey)},
+		{name: "right kid, wrong key", token: signed(jwt.SigningMethodEdDSA, "k1", newEd25519Key(t))},
+		{name: "alg none", token: signed(jwt.SigningMethodNone, "k1", jwt.UnsafeAllowNoneSignatureType)},
+		{name: "HS256 with the legacy secret", token: signed(jwt.SigningMethodHS256, "", []byte("legacy-secret-legacy-secret-legacy"))},
+		// The public key is published in the JWKS, so it must never work as an HMAC secret
+		{name: "HS256 with the public key", token: signed(jwt.SigningMethodHS256, "k1", []byte(edKey.Public().(ed25519.PublicKey)))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verify(k, tt.token); err == nil {
+				t.Fatal("token verified")
+			}
+		})
+	}
+
+	for _, alg := range k.ValidMethods() {
+		if alg != jwt.SigningMethodEdDSA.Alg() {
+			t.Fatalf("ValidMethods lists %s besides the key's algorithm", alg)
+		}
+	}
+}
+
+func TestKeyringLegacyHS256Window(t *testing.T) {
+	dir := newKeyDir(t)
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, testTokenClaims())
+	token, err := legacy.SignedString([]byte("legacy-secret-legacy-secret-legacy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without asymmetric keys the shared secret signs and verifies
+	if err := verify(loadKeyring(t), token); err != nil {
+		t.Fatalf("HS256 without keys: %v", err)
+	}
+
+	writeKey(t, dir, "k1", newEd25519Key(t))
+	t.Setenv("JWT_LEGACY_HS256_UNTIL", time.Now().Add(time.Hour).Format(time.RFC3339))
+	if err := verify(loadKeyring(t), token); err != nil {
+		t.Fatalf("HS256 inside the legacy window: %v", err)
+	}
+
+	t.Setenv("JWT_LEGACY_HS256_UNTIL", time.Now().Add(-time.Hour).Format(time.RFC3339))
+	if err := verify(loadKeyring(t), token); err == nil {
+		t.Fatal("HS256 token verified after the legacy window")
+	}
+}
+
+func TestLoadKeyringRefusesWeakSecretsInProduction(t *testing.T) {
+	newKeyDir(t)
+	t.Setenv("APP_ENV", "production")
+
+	for _, secret := range []string{"", "secret", "too-short"} {
+		t.Setenv("JWT_SECRET", secret)
+		if _, err := LoadKeyringFromEnv(); !errors.Is(err, ErrInsecureJWTConfig) {
+			t.Errorf("JWT_SECRET %q: got %v, want ErrInsecureJWTConfig", secret, err)
+		}
+	}
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	dir := newKeyDir(t)
+	edKey := newEd25519Key(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, dir, "b-ed", edKey)
+	writeKey(t, dir, "a-rsa", rsaKey)
+
+	keys := loadKeyring(t).JWKS()
+	if len(keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(keys))
+	}
+
+	rsaJWK, edJWK := keys[0], keys[1]
+	if rsaJWK.Kid != "a-rsa" || rsaJWK.Kty != "RSA" || rsaJWK.Alg != "RS256" || rsaJWK.Use != "sig" {
+		t.Fatalf("unexpected RSA key %+v", rsaJWK)
+	}
+	if rsaJWK.N != base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()) || rsaJWK.E != "AQAB" {
+		t.Fatalf("RSA key does not match the public key: %+v", rsaJWK)
+	}
+	if edJWK.Kid != "b-ed" || edJWK.Kty != "OKP" || edJWK.Crv != "Ed25519" || edJWK.Alg != "EdDSA" {
+		t.Fatalf("unexpected Ed25519 key %+v", edJWK)
+	}
+	if edJWK.X != base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey)) {
+		t.Fatalf("Ed25519 key does not match the public key: %+v", edJWK)
+	}
+
+	// The HS256 secret is symmetric and never published
+	if keys := (&Keyring{keys: map[string]*SigningKey{}, legacySecret: []byte("secret")}).JWKS(); len(keys) != 0 {
+		t.Fatalf("JWKS without asymmetric keys = %+v, want none", keys)
+	}
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"math/bits"
+	"strconv"
+	"time"
+
+	"devlink/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenTypePoWChallenge marks a signed registration puzzle
+const TokenTypePoWChallenge = "pow_challenge"
+
+// PoWDifficulty is the number of leading zero bits a registration solution needs; 0 turns the gate off
+func PoWDifficulty() int {
+	return config.GetEnvInt("REGISTRATION_POW_DIFFICULTY", 0)
+}
+
+// PoWChallengeTTL is how long a client has to solve a registration puzzle
+func PoWChallengeTTL() time.Duration {
+	return config.GetEnvDuration("REGISTRATION_POW_TTL", 5*time.Minute)
+}
+
+// GeneratePoWChallenge issues a signed hashcash-style puzzle. The signature lets the server
+// check a solution without storing the puzzles it handed out.
+func GeneratePoWChallenge(difficulty int) (string, error) {
+	return signToken(TokenTypePoWChallenge, PoWChallengeTTL(), jwt.MapClaims{
+		"diff": difficulty,
+	})
+}
+
+// VerifyPoW checks that the challenge is genuine and unexpired and that nonce solves it
+// with at least minDifficulty leading zero bits. It returns the challenge's claims so the
+// caller can make sure it is only used once.
+func VerifyPoW(challenge, nonce string, minDifficulty int) (jwt.MapClaims, bool) {
+	claims, err := ParseJWT(challenge, TokenTypePoWChallenge)
+	if err != nil {
+		return nil, false
+	}
+	difficulty, ok := claims["diff"].(float64)
+	if !ok || int(difficulty) < minDifficulty || nonce == "" || len(nonce) > 64 {
+		return nil, false
+	}
+	return claims, PoWLeadingZeroBits(challenge, nonce) >= int(difficulty)
+}
+
+// SolvePoW finds a nonce for the challenge by brute force, as a client would
+func SolvePoW(challenge string, difficulty int) string {
+	for i := uint64(0); ; i++ {
+		nonce := strconv.FormatUint(i, 10)
+		if PoWLeadingZeroBits(challenge, nonce) >= difficulty {
+			return nonce
+		}
+	}
+}
+
+// PoWLeadingZeroBits counts the leading zero bits of SHA-256(challenge + ":" + nonce)
+func PoWLeadingZeroBits(challenge, nonce string) int {
+	sum := sha256.Sum256([]byte(challenge + ":" + nonce))
+	count := 0
+	for _, b := range sum {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
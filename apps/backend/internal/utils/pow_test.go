@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyPoW(t *testing.T) {
+	const difficulty = 8
+
+	tests := []struct {
+		name string
+		// challenge returns the puzzle and the nonce the client sends back for it
+		challenge func(t *testing.T) (string, string)
+		want      bool
+	}{
+		{
+			name: "valid solution",
+			challenge: func(t *testing.T) (string, string) {
+				challenge := newPoWChallenge(t, difficulty)
+				return challenge, SolvePoW(challenge, difficulty)
+			},
+			want: true,
+		},
+		{
+			name: "nonce misses the target",
+			challenge: func(t *testing.T) (string, string) {
+				challenge := newPoWChallenge(t, difficulty)
+				return challenge, missPoW(challenge, difficulty)
+			},
+		},
+		{
+			name: "challenge easier than required",
+			challenge: func(t *testing.T) (string, string) {
+				challenge := newPoWChallenge(t, difficulty-4)
+				return challenge, SolvePoW(challenge, difficulty-4)
+			},
+		},
+		{
+			name: "expired challenge",
+			challenge: func(t *testing.T) (string, string) {
+				t.Setenv("REGISTRATION_POW_TTL", "-1m")
+				challenge := newPoWChallenge(t, difficulty)
+				return challenge, SolvePoW(challenge, difficulty)
+			},
+		},
+		{
+			name: "tampered signature",
+			challenge: func(t *testing.T) (string, string) {
+				challenge := newPoWChallenge(t, difficulty)
+				dot := strings.LastIndex(challenge, ".")
+				swapped := "A"
+				if challenge[dot+1] == 'A' {
+					swapped = "B"
+				}
+				// Solve the tampered puzzle so only the signature can fail
+				tampered := challenge[:dot+1] + swapped + challenge[dot+2:]
+				return tampered, SolvePoW(tampered, difficulty)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge, nonce := tt.challenge(t)
+			claims, ok := VerifyPoW(challenge, nonce, difficulty)
+			if ok != tt.want {
+				t.Fatalf("VerifyPoW() = %v, want %v", ok, tt.want)
+			}
+			if ok {
+				if jti, _ := claims["jti"].(string); jti == "" {
+					t.Fatal("a solved challenge must carry a jti for replay protection")
+				}
+			}
+		})
+	}
+}
+
+func newPoWChallenge(t *testing.T, difficulty int) string {
+	t.Helper()
+	challenge, err := GeneratePoWChallenge(difficulty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return challenge
+}
+
+// missPoW finds a nonce that does not solve the challenge
+func missPoW(challenge string, difficulty int) string {
+	for nonce := "x"; ; nonce += "x" {
+		if PoWLeadingZeroBits(challenge, nonce) < difficulty {
+			return nonce
+		}
+	}
+}
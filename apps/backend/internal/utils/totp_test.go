@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed of the RFC 6238 test vectors, "12345678901234567890", in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// Appendix B lists 8-digit codes; the 6-digit codes apps show are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	// Secrets typed in by hand are often lowercase
+	if got, _ := TOTPCode(strings.ToLower(rfc6238Secret), 1); got != "287082" {
+		t.Errorf("lowercase secret gave %s, want 287082", got)
+	}
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("invalid secret accepted")
+	}
+}
+
+func TestValidateTOTPSkewWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := TOTPStep(now)
+	codeAt := func(step int64) string {
+		code, err := TOTPCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name  string
+		code  string
+		valid bool
+	}{
+		{name: "current step", code: codeAt(current), valid: true},
+		{name: "previous step", code: codeAt(current - 1), valid: true},
+		{name: "next step", code: codeAt(current + 1), valid: true},
+		{name: "two steps back", code: codeAt(current - 2), valid: false},
+		{name: "two steps ahead", code: codeAt(current + 2), valid: false},
+		{name: "spaces ignored", code: " " + codeAt(current)[:3] + " " + codeAt(current)[3:] + " ", valid: true},
+		{name: "too short", code: codeAt(current)[:5], valid: false},
+		{name: "too long", code: codeAt(current) + "0", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, tt.code, now)
+			if ok != tt.valid {
+				t.Fatalf("ValidateTOTP = %v, want %v", ok, tt.valid)
+			}
+			if ok && (step < current-totpSkew || step > current+totpSkew) {
+				t.Fatalf("matched step %d outside the window around %d", step, current)
+			}
+		})
+	}
+
+	// The matched step is reported so callers can refuse it next time
+	if step, _ := ValidateTOTP(rfc6238Secret, codeAt(current-1), now); step != current-1 {
+		t.Fatalf("matched step %d, want %d", step, current-1)
+	}
+	if _, ok := ValidateTOTP("", codeAt(current), now); ok {
+		t.Fatal("code accepted without a secret")
+	}
+}
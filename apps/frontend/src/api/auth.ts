@@ -1,8 +1,11 @@
 export const loginUser = async (email: string, password: string) => {
   const response = await fetch('http://localhost:8080/users/login', {
     method: 'POST',
+    // Session mode: the API keeps tokens in HttpOnly cookies instead of returning them
+    credentials: 'include',
     headers: {
       'Content-Type': 'application/json',
+      'X-Auth-Mode': 'cookie',
     },
     body: JSON.stringify({ email, password }),
   })
@@ -18,8 +21,11 @@ export const loginUser = async (email: string, password: string) => {
 export const registerUser = async (username: string, email: string, password: string) => {
   const response = await fetch('http://localhost:8080/users/register', {
     method: 'POST',
+    // Session mode: the API keeps tokens in HttpOnly cookies instead of returning them
+    credentials: 'include',
     headers: {
       'Content-Type': 'application/json',
+      'X-Auth-Mode': 'cookie',
     },
     body: JSON.stringify({ username, email, password }),
   })